@@ -0,0 +1,188 @@
+package jsongofpdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"strings"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/code39"
+	"github.com/boombuler/barcode/ean"
+	"github.com/boombuler/barcode/twooffive"
+	"github.com/jung-kurt/gofpdf"
+	"github.com/spf13/cast"
+)
+
+var (
+	ErrBarcodeType  = errors.New("Unsupported barcode type")
+	ErrBarcodeValue = errors.New("Barcode value is empty")
+)
+
+// barcodeModuleWidth is the width of a bar module in millimetres when no barcode width is given, the EAN nominal size.
+const barcodeModuleWidth = 0.33
+
+// barcodeHeight is the height of a barcode in millimetres when none is given.
+const barcodeHeight = 15.0
+
+// EncodeBarcode encodes value as a linear barcode of kind "code128", "code39", "ean13", "ean8", "upca", "2of5" or "i2of5".
+// EAN and UPC check digits are added when left off and checked when given, checksum adds the optional check character of
+// Code 39 and 2 of 5. The human readable text of the barcode is returned with it.
+func EncodeBarcode(kind string, value string, checksum bool) (code barcode.Barcode, readable string, err error) {
+	if value == "" {
+		return nil, "", ErrBarcodeValue
+	}
+
+	switch strings.ToLower(kind) {
+	case "code128":
+		code, err = code128.Encode(value)
+		break
+	case "code39":
+		code, err = code39.Encode(value, checksum, false)
+		break
+	case "ean13", "ean8":
+		if length := len(value); (kind == "ean13" && length != 12 && length != 13) || (kind == "ean8" && length != 7 && length != 8) {
+			return nil, "", fmt.Errorf("Invalid %s length %d", kind, length)
+		}
+		code, err = ean.Encode(value)
+		break
+	case "upca":
+		// UPC-A is an EAN-13 with a leading zero
+		if len(value) != 11 && len(value) != 12 {
+			return nil, "", fmt.Errorf("Invalid upca length %d", len(value))
+		}
+		code, err = ean.Encode("0" + value)
+		if err == nil {
+			return code, code.Content()[1:], nil
+		}
+		break
+	case "2of5", "i2of5":
+		if checksum {
+			if value, err = twooffive.AddCheckSum(value); err != nil {
+				return nil, "", err
+			}
+		}
+		code, err = twooffive.Encode(value, kind == "i2of5")
+		break
+	default:
+		return nil, "", ErrBarcodeType
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return code, code.Content(), nil
+}
+
+// RegisterBarcode registers code with gofpdf under name as a lossless image with one pixel per module so it stays sharp at
+// any size. Linear barcodes are one pixel high and stretched to their height when placed.
+func RegisterBarcode(pdf *gofpdf.Fpdf, name string, code barcode.Barcode) (info *gofpdf.ImageInfoType, err error) {
+	if info = pdf.GetImageInfo(name); info != nil {
+		return info, nil
+	}
+
+	bounds := code.Bounds()
+	gray := image.NewGray(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(gray, gray.Bounds(), code, bounds.Min, draw.Src)
+	buffer := new(bytes.Buffer)
+	if err = png.Encode(buffer, gray); err != nil {
+		return nil, err
+	}
+
+	info = pdf.RegisterImageOptionsReader(name, gofpdf.ImageOptions{ImageType: "png"}, buffer)
+	if pdf.Err() {
+		return nil, pdf.Error()
+	}
+	return info, nil
+}
+
+// Barcode maps json to a linear barcode drawn as an image. Pass in "type" string as "code128", "code39", "ean13", "ean8",
+// "upca", "2of5" or "i2of5" and the value as "text" string, "data" string as a dotted path into the bound Data or "target"
+// string as a table cell with "attribute" and "loop" as for MultiCell. "checksum" bool adds the optional Code 39 and 2 of 5
+// check character. Pass "x", "y", "width", "height" float, "flow" bool, "link" int, "linkstr" string properties placed like
+// Image, a width of 0 sizing each module 0.33mm and a height of 0 making the bars 15mm high. "readable" bool writes the
+// value under the bars in the current font in a line of "textheight" float. Nothing is drawn for an empty value.
+// Defaults are "type": "code128", "text": "", "data": "", "target": "", "attribute": "value", "loop": false, "checksum": false,
+// "x": 0.0, "y": 0.0, "width": 0.0, "height": 0.0, "flow": false, "link": -1, "linkstr": "", "readable": false, "textheight": 0.0
+func (p *JSONGOFPDF) Barcode(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
+	kind := strings.ToLower(p.GetString("type", logic, "code128"))
+	checksum := p.GetBool("checksum", logic, false)
+	x := p.GetFloat("x", logic, 0.0)
+	y := p.GetFloat("y", logic, 0.0)
+	width := p.GetFloat("width", logic, 0.0)
+	height := p.GetFloat("height", logic, 0.0)
+	flow := p.GetBool("flow", logic, false)
+	link := p.GetInt("link", logic, -1)
+	linkStr := p.GetString("linkstr", logic, "")
+	readable := p.GetBool("readable", logic, false)
+	textHeight := p.GetFloat("textheight", logic, 0.0)
+
+	value := p.GetBarcodeValue(logic)
+	if value == "" {
+		return pdf
+	}
+	code, readableText, err := EncodeBarcode(kind, value, checksum)
+	if err != nil {
+		fmt.Println(err)
+		return pdf
+	}
+	name := "barcode:" + kind + ":" + code.Content()
+	if _, err = RegisterBarcode(pdf, name, code); err != nil {
+		fmt.Println(err)
+		return pdf
+	}
+
+	// Millimetres in document units
+	mm := 72 / 25.4 / pdf.GetConversionRatio()
+	if width == 0 {
+		width = float64(code.Bounds().Dx()) * barcodeModuleWidth * mm
+	}
+	if height == 0 {
+		height = barcodeHeight * mm
+	}
+	if flow {
+		y = pdf.GetY()
+	}
+	pdf.ImageOptions(name, x, y, width, height, false, gofpdf.ImageOptions{}, link, linkStr)
+	y += height
+
+	if readable {
+		cursorX, cursorY := pdf.GetXY()
+		if textHeight == 0 {
+			_, textHeight = pdf.GetFontSize()
+		}
+		pdf.SetXY(x, y)
+		pdf.CellFormat(width, textHeight, p.tr(readableText), "", 0, "C", false, 0, "")
+		y += textHeight
+		pdf.SetXY(cursorX, cursorY)
+	}
+	if flow {
+		pdf.SetY(y)
+	}
+
+	return pdf
+}
+
+// GetBarcodeValue returns the value of a barcode or code operation from its "text", "data" path or "target" table cell,
+// which is the current cell when no target is given. Outside a table it returns "" unless a source is given.
+func (p *JSONGOFPDF) GetBarcodeValue(logic string) string {
+	if text := p.GetString("text", logic, ""); text != "" {
+		return text
+	}
+	if dataPath := p.GetString("data", logic, ""); dataPath != "" {
+		value, _ := p.GetDataString(dataPath)
+		return value
+	}
+	target := p.GetString("target", logic, "")
+	if target == "" && !p.inTableBody && !p.inTableSection {
+		return ""
+	}
+	cell := p.GetCell(target, "", p.GetBool("loop", logic, false))
+	if p.GetString("attribute", logic, "value") == "title" {
+		return cell.Title
+	}
+	return cast.ToString(cell.Value)
+}
@@ -5,11 +5,26 @@ import (
 	"github.com/spf13/cast"
 )
 
+// Calculation aggregates the json-logic "formula" over the Data of the table by "type" "count", "sum", "minimum",
+// "maximum" or "average", shortened to "min", "max" and "avg", or evaluates it for the current row without a type.
+// Pass "scope" as "rendered" to only aggregate the rows rendered so far, "page" for the rows rendered on the current page
+// or "group" for the rows of the group being rendered. Default is "scope": "all"
 func (p *JSONGOFPDF) Calculation(logic, value string) string {
 	result := 0.0
 	calcType := p.GetString("type", logic, "")
 	formula := p.GetString("formula", logic, "")
 	table := p.Tables[p.TableIndex]
+	switch p.GetString("scope", logic, "all") {
+	case "rendered":
+		table.Data = p.renderedData
+		break
+	case "page":
+		table.Data = p.pageRenderedData
+		break
+	case "group":
+		table.Data = p.currentGroupData
+		break
+	}
 	switch calcType {
 	case "count":
 		for _, data := range table.Data {
@@ -25,7 +40,7 @@ func (p *JSONGOFPDF) Calculation(logic, value string) string {
 			result += cast.ToFloat64(logicresult)
 		}
 		break
-	case "minimum":
+	case "minimum", "min":
 		for i, data := range table.Data {
 			logicresult, _ := jsonlogic.Apply(formula, data)
 			if i > 0 {
@@ -37,7 +52,7 @@ func (p *JSONGOFPDF) Calculation(logic, value string) string {
 			}
 		}
 		break
-	case "maximum":
+	case "maximum", "max":
 		for i, data := range table.Data {
 			logicresult, _ := jsonlogic.Apply(formula, data)
 			if i > 0 {
@@ -49,16 +64,18 @@ func (p *JSONGOFPDF) Calculation(logic, value string) string {
 			}
 		}
 		break
-	case "average":
+	case "average", "avg":
 		for _, data := range table.Data {
 			logicresult, _ := jsonlogic.Apply(formula, data)
 			result += cast.ToFloat64(logicresult)
 		}
-		result = result / cast.ToFloat64(len(table.Data))
+		if len(table.Data) > 0 {
+			result = result / cast.ToFloat64(len(table.Data))
+		}
 		break
 	default:
-		if len(table.Data)-1 >= p.RowIndex {
-			logicresult, _ := jsonlogic.Apply(formula, table.Data[p.RowIndex])
+		if data := p.Tables[p.TableIndex].Data; len(data)-1 >= p.RowIndex {
+			logicresult, _ := jsonlogic.Apply(formula, data[p.RowIndex])
 			result = cast.ToFloat64(logicresult)
 		}
 		break
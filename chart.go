@@ -0,0 +1,479 @@
+package jsongofpdf
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	jsonlogic "github.com/GeorgeD19/json-logic-go"
+	"github.com/buger/jsonparser"
+	"github.com/jung-kurt/gofpdf"
+	"github.com/spf13/cast"
+)
+
+// chartPalette colours the series of a chart, or the slices of a pie, that do not give their own colour.
+var chartPalette = []string{"#4e79a7", "#f28e2b", "#e15759", "#76b7b2", "#59a14f", "#edc948", "#b07aa1", "#ff9da7", "#9c755f", "#bab0ac"}
+
+// ChartSeries is a named series of values, one for each category of a chart.
+type ChartSeries struct {
+	Name   string
+	Color  string
+	Values []float64
+}
+
+// Chart is the data of a chart, the category labels along its axis and the series plotted against them.
+type Chart struct {
+	Labels []string
+	Series []ChartSeries
+}
+
+// GetChart reads the labels and series of a chart from the Data of the table at index. Each row of Data is one category,
+// labelled by the json-logic formula labels, with each series valued by its own formula as Calculation does.
+func (p *JSONGOFPDF) GetChart(index int, labels string, series string, palette []string) (chart Chart) {
+	if index >= len(p.Tables) {
+		return chart
+	}
+	data := p.Tables[index].Data
+
+	for row, value := range data {
+		label := strconv.Itoa(row + 1)
+		if labels != "" {
+			if result, err := jsonlogic.Apply(labels, value); err == nil && result != nil {
+				label = cast.ToString(result)
+			}
+		}
+		chart.Labels = append(chart.Labels, label)
+	}
+
+	jsonparser.ArrayEach([]byte(series), func(value []byte, dataType jsonparser.ValueType, offset int, err error) {
+		if dataType != jsonparser.Object {
+			return
+		}
+		logic := string(value)
+		formula := p.GetString("formula", logic, "")
+		entry := ChartSeries{
+			Name:  p.GetString("name", logic, ""),
+			Color: p.GetString("color", logic, palette[len(chart.Series)%len(palette)]),
+		}
+		for _, row := range data {
+			result, _ := jsonlogic.Apply(formula, row)
+			entry.Values = append(entry.Values, cast.ToFloat64(result))
+		}
+		chart.Series = append(chart.Series, entry)
+	})
+
+	return chart
+}
+
+// chartBox is the area a chart is drawn into.
+type chartBox struct {
+	x      float64
+	y      float64
+	width  float64
+	height float64
+}
+
+// Chart maps json to a vector chart of the Data of a table. Pass in "type" string as "bar", "stackedbar", "line", "area",
+// "pie" or "donut", "index" int for the table, "labels" json-logic formula for the category of each row and "series" array
+// of objects with "name" string, "formula" json-logic and "color" string. Pie and donut charts plot the first series with
+// a slice for each category. Pass "x", "y", "width", "height" float for the box, "title" string, "legend" string as
+// "bottom", "right" or "none", "gridlines" bool, "ticks" int for the number of value axis steps, "min" and "max" float to
+// fix the value axis, "values" bool to label each value, "format" string to format the values as CellFormat does,
+// "palette" array of colour strings, "fontsize" float and "hole" float for the donut hole as a fraction of the radius.
+// Defaults are "type": "bar", "index": 0, "labels": "", "series": [], "x": 0.0, "y": 0.0, "width": 100.0, "height": 60.0,
+// "title": "", "legend": "bottom", "gridlines": true, "ticks": 5, "values": false, "format": "", "fontsize": 8.0, "hole": 0.5
+func (p *JSONGOFPDF) Chart(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
+	chartType := p.GetString("type", logic, "bar")
+	title := p.GetString("title", logic, "")
+	legend := p.GetString("legend", logic, "bottom")
+	fontSize := p.GetFloat("fontsize", logic, 8.0)
+	box := chartBox{
+		x:      p.GetFloat("x", logic, 0.0),
+		y:      p.GetFloat("y", logic, 0.0),
+		width:  p.GetFloat("width", logic, 100.0),
+		height: p.GetFloat("height", logic, 60.0),
+	}
+
+	palette := make([]string, 0)
+	jsonparser.ArrayEach([]byte(p.GetString("palette", logic, "")), func(value []byte, dataType jsonparser.ValueType, offset int, err error) {
+		palette = append(palette, string(value))
+	})
+	if len(palette) == 0 {
+		palette = chartPalette
+	}
+
+	chart := p.GetChart(p.GetInt("index", logic, 0), p.GetString("labels", logic, ""), p.GetString("series", logic, ""), palette)
+	if len(chart.Series) == 0 || len(chart.Labels) == 0 {
+		fmt.Println("Chart has no series or data")
+		return pdf
+	}
+
+	defer p.saveGraphics(pdf)()
+	pdf.SetFont("", "", fontSize)
+	pdf.SetCellMargin(0)
+	pdf.SetTextColor(64, 64, 64)
+	pdf.SetLineWidth(0.5 / pdf.GetConversionRatio())
+	_, lineHeight := pdf.GetFontSize()
+	lineHeight *= 1.4
+
+	if title != "" {
+		pdf.SetFont("", "B", fontSize*1.2)
+		pdf.SetXY(box.x, box.y)
+		pdf.CellFormat(box.width, lineHeight*1.2, p.tr(title), "", 0, "C", false, 0, "")
+		pdf.SetFont("", "", fontSize)
+		box.y += lineHeight * 1.4
+		box.height -= lineHeight * 1.4
+	}
+
+	// Pie slices are the categories, otherwise the legend lists the series
+	entries := make([]ChartSeries, 0)
+	if chartType == "pie" || chartType == "donut" {
+		for index, label := range chart.Labels {
+			entries = append(entries, ChartSeries{Name: label, Color: palette[index%len(palette)]})
+		}
+	} else {
+		entries = chart.Series
+	}
+	if legend != "none" {
+		box = p.drawChartLegend(pdf, box, entries, legend, lineHeight)
+	}
+
+	format := p.GetString("format", logic, "")
+	values := p.GetBool("values", logic, false)
+	switch chartType {
+	case "pie", "donut":
+		hole := 0.0
+		if chartType == "donut" {
+			hole = p.GetFloat("hole", logic, 0.5)
+		}
+		p.drawPieChart(pdf, box, chart.Series[0].Values, entries, hole, values, format)
+		break
+	default:
+		p.drawAxisChart(pdf, box, chart, chartType, logic, lineHeight, values, format)
+	}
+
+	return pdf
+}
+
+// formatChartValue formats a value for a label by format, or with as many decimals as it needs.
+func (p *JSONGOFPDF) formatChartValue(value float64, format string) string {
+	text := strconv.FormatFloat(value, 'f', -1, 64)
+	if math.Abs(value-math.Round(value)) > 0 {
+		text = strconv.FormatFloat(value, 'f', 2, 64)
+	}
+	if format != "" {
+		text = p.Format(format, text)
+	}
+	return p.tr(text)
+}
+
+// setChartColor sets the fill and draw colours to color, falling back to grey when it cannot be parsed.
+func setChartColor(pdf *gofpdf.Fpdf, color string) {
+	r, g, b, ok := ParseColor(color)
+	if !ok {
+		r, g, b = 128, 128, 128
+	}
+	pdf.SetFillColor(r, g, b)
+	pdf.SetDrawColor(r, g, b)
+}
+
+// drawChartLegend draws a swatch and name for each entry below or to the right of box, returning the box left for the plot.
+func (p *JSONGOFPDF) drawChartLegend(pdf *gofpdf.Fpdf, box chartBox, entries []ChartSeries, position string, lineHeight float64) chartBox {
+	swatch := lineHeight * 0.6
+	widths := make([]float64, len(entries))
+	widest := 0.0
+	for index, entry := range entries {
+		widths[index] = swatch + lineHeight*0.3 + pdf.GetStringWidth(p.tr(entry.Name)) + lineHeight
+		widest = math.Max(widest, widths[index])
+	}
+
+	place := func(index int, x float64, y float64) {
+		setChartColor(pdf, entries[index].Color)
+		pdf.Rect(x, y+(lineHeight-swatch)/2, swatch, swatch, "F")
+		pdf.SetXY(x+swatch+lineHeight*0.3, y)
+		pdf.CellFormat(widths[index]-swatch, lineHeight, p.tr(entries[index].Name), "", 0, "L", false, 0, "")
+	}
+
+	if position == "right" {
+		box.width -= widest
+		for index := range entries {
+			place(index, box.x+box.width+lineHeight*0.5, box.y+float64(index)*lineHeight)
+		}
+		return box
+	}
+
+	// Entries wrap onto as many centred rows as they need
+	rows := [][]int{{}}
+	rowWidth := 0.0
+	for index := range entries {
+		if rowWidth+widths[index] > box.width && len(rows[len(rows)-1]) > 0 {
+			rows = append(rows, []int{})
+			rowWidth = 0
+		}
+		rows[len(rows)-1] = append(rows[len(rows)-1], index)
+		rowWidth += widths[index]
+	}
+	box.height -= float64(len(rows)) * lineHeight
+	for row, indexes := range rows {
+		width := 0.0
+		for _, index := range indexes {
+			width += widths[index]
+		}
+		x := box.x + (box.width-width)/2
+		for _, index := range indexes {
+			place(index, x, box.y+box.height+float64(row)*lineHeight)
+			x += widths[index]
+		}
+	}
+	return box
+}
+
+// chartScale chooses a value axis from minimum to maximum in about ticks steps of 1, 2 or 5 times a power of ten.
+func chartScale(minimum float64, maximum float64, ticks int) (low float64, high float64, step float64) {
+	if maximum == minimum {
+		maximum = minimum + 1
+	}
+	if ticks < 1 {
+		ticks = 1
+	}
+	raw := (maximum - minimum) / float64(ticks)
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	step = 10 * magnitude
+	for _, nice := range []float64{1, 2, 2.5, 5} {
+		if raw <= nice*magnitude {
+			step = nice * magnitude
+			break
+		}
+	}
+	return math.Floor(minimum/step+1e-9) * step, math.Ceil(maximum/step-1e-9) * step, step
+}
+
+// drawAxisChart draws a bar, stacked bar, line or area chart with its axes, gridlines and labels into box.
+func (p *JSONGOFPDF) drawAxisChart(pdf *gofpdf.Fpdf, box chartBox, chart Chart, chartType string, logic string, lineHeight float64, values bool, format string) {
+	stacked := chartType == "stackedbar"
+	categories := len(chart.Labels)
+
+	// The value range includes zero so bars and areas grow from the axis
+	minimum, maximum := 0.0, 0.0
+	for category := 0; category < categories; category++ {
+		positive, negative := 0.0, 0.0
+		for _, series := range chart.Series {
+			value := series.Values[category]
+			if stacked {
+				if value > 0 {
+					positive += value
+				} else {
+					negative += value
+				}
+			} else {
+				maximum, minimum = math.Max(maximum, value), math.Min(minimum, value)
+			}
+		}
+		if stacked {
+			maximum, minimum = math.Max(maximum, positive), math.Min(minimum, negative)
+		}
+	}
+	if _, _, _, err := p.GetAttribute("min", logic, false); err == nil {
+		minimum = p.GetFloat("min", logic, minimum)
+	}
+	if _, _, _, err := p.GetAttribute("max", logic, false); err == nil {
+		maximum = p.GetFloat("max", logic, maximum)
+	}
+	low, high, step := chartScale(minimum, maximum, p.GetInt("ticks", logic, 5))
+
+	// The value labels set the width of the axis on the left and the category labels the height below
+	axisWidth := 0.0
+	for tick := low; tick <= high+step/2; tick += step {
+		axisWidth = math.Max(axisWidth, pdf.GetStringWidth(p.formatChartValue(tick, format)))
+	}
+	axisWidth += lineHeight * 0.4
+	plot := chartBox{x: box.x + axisWidth, y: box.y + lineHeight/2, width: box.width - axisWidth, height: box.height - lineHeight*1.5}
+	if plot.width <= 0 || plot.height <= 0 {
+		return
+	}
+	valueY := func(value float64) float64 {
+		return plot.y + plot.height - (value-low)/(high-low)*plot.height
+	}
+
+	gridlines := p.GetBool("gridlines", logic, true)
+	for tick := low; tick <= high+step/2; tick += step {
+		y := valueY(tick)
+		if gridlines {
+			pdf.SetDrawColor(224, 224, 224)
+			pdf.Line(plot.x, y, plot.x+plot.width, y)
+		}
+		pdf.SetXY(box.x, y-lineHeight/2)
+		pdf.CellFormat(axisWidth-lineHeight*0.4, lineHeight, p.formatChartValue(tick, format), "", 0, "R", false, 0, "")
+	}
+
+	groupWidth := plot.width / float64(categories)
+	for category, label := range chart.Labels {
+		text := p.tr(label)
+		for len(text) > 1 && pdf.GetStringWidth(text) > groupWidth {
+			text = text[:len(text)-1]
+		}
+		pdf.SetXY(plot.x+float64(category)*groupWidth, plot.y+plot.height+lineHeight*0.2)
+		pdf.CellFormat(groupWidth, lineHeight, text, "", 0, "C", false, 0, "")
+	}
+
+	zero := valueY(math.Max(low, math.Min(0, high)))
+	switch chartType {
+	case "line", "area":
+		for _, series := range chart.Series {
+			points := make([]gofpdf.PointType, categories)
+			for category, value := range series.Values {
+				points[category] = gofpdf.PointType{X: plot.x + (float64(category)+0.5)*groupWidth, Y: valueY(value)}
+			}
+			setChartColor(pdf, series.Color)
+			if chartType == "area" {
+				area := append([]gofpdf.PointType{{X: points[0].X, Y: zero}}, points...)
+				area = append(area, gofpdf.PointType{X: points[categories-1].X, Y: zero})
+				pdf.SetAlpha(0.35, "Normal")
+				pdf.Polygon(area, "F")
+				pdf.SetAlpha(1, "Normal")
+			}
+			pdf.SetLineWidth(1.5 / pdf.GetConversionRatio())
+			pdf.MoveTo(points[0].X, points[0].Y)
+			for _, point := range points[1:] {
+				pdf.LineTo(point.X, point.Y)
+			}
+			pdf.DrawPath("D")
+			pdf.SetLineWidth(0.5 / pdf.GetConversionRatio())
+			for category, point := range points {
+				pdf.Circle(point.X, point.Y, lineHeight*0.12, "F")
+				if values {
+					p.drawChartValue(pdf, point.X, point.Y-lineHeight*0.2, series.Values[category], format, lineHeight, true)
+				}
+			}
+		}
+		break
+	default:
+		barArea := groupWidth * 0.8
+		barWidth := barArea
+		if !stacked {
+			barWidth = barArea / float64(len(chart.Series))
+		}
+		for category := 0; category < categories; category++ {
+			groupX := plot.x + float64(category)*groupWidth + (groupWidth-barArea)/2
+			positive, negative := 0.0, 0.0
+			for index, series := range chart.Series {
+				value := series.Values[category]
+				x, from := groupX+float64(index)*barWidth, 0.0
+				if stacked {
+					x = groupX
+					if value >= 0 {
+						from = positive
+						positive += value
+					} else {
+						from = negative
+						negative += value
+					}
+				}
+				top, bottom := valueY(from+value), valueY(from)
+				setChartColor(pdf, series.Color)
+				pdf.Rect(x, math.Min(top, bottom), barWidth, math.Abs(bottom-top), "F")
+				if values && value != 0 {
+					if stacked {
+						pdf.SetTextColor(255, 255, 255)
+						p.drawChartValue(pdf, x+barWidth/2, (top+bottom)/2+lineHeight/2, value, format, lineHeight, true)
+						pdf.SetTextColor(64, 64, 64)
+					} else {
+						p.drawChartValue(pdf, x+barWidth/2, top-lineHeight*0.1, value, format, lineHeight, value >= 0)
+					}
+				}
+			}
+		}
+	}
+
+	// The value axis and the zero line are drawn over the plot
+	pdf.SetDrawColor(96, 96, 96)
+	pdf.Line(plot.x, plot.y, plot.x, plot.y+plot.height)
+	pdf.Line(plot.x, zero, plot.x+plot.width, zero)
+}
+
+// drawChartValue writes a value label centred on x, ending at y when above or starting at y when below.
+func (p *JSONGOFPDF) drawChartValue(pdf *gofpdf.Fpdf, x float64, y float64, value float64, format string, lineHeight float64, above bool) {
+	text := p.formatChartValue(value, format)
+	width := pdf.GetStringWidth(text)
+	if above {
+		y -= lineHeight
+	}
+	pdf.SetXY(x-width/2, y)
+	pdf.CellFormat(width, lineHeight, text, "", 0, "C", false, 0, "")
+}
+
+// drawPieChart draws a slice for each value clockwise from the top, leaving a hole of a fraction of the radius for donuts.
+func (p *JSONGOFPDF) drawPieChart(pdf *gofpdf.Fpdf, box chartBox, values []float64, entries []ChartSeries, hole float64, labels bool, format string) {
+	total := 0.0
+	for _, value := range values {
+		if value > 0 {
+			total += value
+		}
+	}
+	if total <= 0 {
+		return
+	}
+
+	radius := math.Min(box.width, box.height) / 2 * 0.95
+	centreX, centreY := box.x+box.width/2, box.y+box.height/2
+	inner := radius * math.Max(0, math.Min(hole, 0.95))
+
+	pdf.SetDrawColor(255, 255, 255)
+	angle := 0.0
+	for index, value := range values {
+		if value <= 0 {
+			continue
+		}
+		sweep := value / total * 2 * math.Pi
+		setChartColor(pdf, entries[index].Color)
+		pdf.SetDrawColor(255, 255, 255)
+		if inner > 0 {
+			pdf.MoveTo(centreX+inner*math.Sin(angle), centreY-inner*math.Cos(angle))
+			pdf.LineTo(centreX+radius*math.Sin(angle), centreY-radius*math.Cos(angle))
+			chartArc(pdf, centreX, centreY, radius, angle, angle+sweep)
+			pdf.LineTo(centreX+inner*math.Sin(angle+sweep), centreY-inner*math.Cos(angle+sweep))
+			chartArc(pdf, centreX, centreY, inner, angle+sweep, angle)
+		} else {
+			pdf.MoveTo(centreX, centreY)
+			pdf.LineTo(centreX+radius*math.Sin(angle), centreY-radius*math.Cos(angle))
+			chartArc(pdf, centreX, centreY, radius, angle, angle+sweep)
+		}
+		pdf.ClosePath()
+		pdf.DrawPath("FD")
+
+		if labels {
+			// Percentages sit in the middle of the ring or two thirds out on a pie
+			middle := angle + sweep/2
+			distance := (radius + inner) / 2
+			if inner == 0 {
+				distance = radius * 0.65
+			}
+			_, lineHeight := pdf.GetFontSize()
+			text := strconv.FormatFloat(value/total*100, 'f', 0, 64) + "%"
+			if format != "" {
+				text = p.formatChartValue(value, format)
+			}
+			pdf.SetTextColor(255, 255, 255)
+			pdf.SetXY(centreX+distance*math.Sin(middle)-radius/2, centreY-distance*math.Cos(middle)-lineHeight/2)
+			pdf.CellFormat(radius, lineHeight, p.tr(text), "", 0, "C", false, 0, "")
+		}
+		angle += sweep
+	}
+}
+
+// chartArc continues the current path along a circle of radius around centreX, centreY from angle start to end in
+// radians clockwise from the top, in cubic Bézier segments of at most a quarter turn.
+func chartArc(pdf *gofpdf.Fpdf, centreX float64, centreY float64, radius float64, start float64, end float64) {
+	segments := int(math.Ceil(math.Abs(end-start) / (math.Pi / 2)))
+	if segments < 1 {
+		segments = 1
+	}
+	step := (end - start) / float64(segments)
+	handle := 4.0 / 3.0 * math.Tan(step/4) * radius
+	for segment := 0; segment < segments; segment++ {
+		from, to := start+float64(segment)*step, start+float64(segment+1)*step
+		fromX, fromY := centreX+radius*math.Sin(from), centreY-radius*math.Cos(from)
+		toX, toY := centreX+radius*math.Sin(to), centreY-radius*math.Cos(to)
+		pdf.CurveBezierCubicTo(fromX+handle*math.Cos(from), fromY+handle*math.Sin(from), toX-handle*math.Cos(to), toY-handle*math.Sin(to), toX, toY)
+	}
+}
@@ -0,0 +1,195 @@
+package jsongofpdf
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestChartScale(t *testing.T) {
+	tests := []struct {
+		minimum, maximum float64
+		ticks            int
+		low, high, step  float64
+	}{
+		{0, 210, 5, 0, 250, 50},
+		{-20, 210, 5, -50, 250, 50},
+		{0, 1, 4, 0, 1, 0.25},
+		{0, 0, 5, 0, 1, 0.2},
+		{3, 7, 2, 2, 8, 2},
+	}
+
+	for _, test := range tests {
+		low, high, step := chartScale(test.minimum, test.maximum, test.ticks)
+		if low != test.low || high != test.high || step != test.step {
+			t.Fatalf("chartScale(%v, %v, %d) should be %v, %v, %v, got %v, %v, %v", test.minimum, test.maximum, test.ticks, test.low, test.high, test.step, low, high, step)
+		}
+	}
+}
+
+// pageShape is a path painted on a page with paint operator op, the fill colour set before it and its points in mm
+// from the top left of the page, the end point of each curve.
+type pageShape struct {
+	op     string
+	fill   string
+	points [][2]float64
+}
+
+// renderShapes renders logic over tables and returns the shapes painted on the first page.
+func renderShapes(t *testing.T, logic string, tables []Table) (shapes []pageShape) {
+	streams, pdf := renderStreams(t, logic, tables)
+	_, pageHeight := pdf.GetPageSize()
+	ratio := pdf.GetConversionRatio()
+	point := func(operands []float64) [2]float64 {
+		return [2]float64{operands[0] / ratio, pageHeight - operands[1]/ratio}
+	}
+
+	fill, path, operands := "", [][2]float64{}, []float64{}
+	for _, token := range strings.Fields(streams[0]) {
+		if number, err := strconv.ParseFloat(token, 64); err == nil {
+			operands = append(operands, number)
+			continue
+		}
+		switch token {
+		case "rg":
+			if len(operands) == 3 {
+				fill = strings.Trim(fmt.Sprint(operands), "[]")
+			}
+		case "m":
+			path = [][2]float64{point(operands[len(operands)-2:])}
+		case "l", "c":
+			path = append(path, point(operands[len(operands)-2:]))
+		case "re":
+			corner, size := point(operands[:2]), [2]float64{operands[2] / ratio, -operands[3] / ratio}
+			path = [][2]float64{corner, {corner[0] + size[0], corner[1] + size[1]}}
+		case "f", "S", "B":
+			shapes = append(shapes, pageShape{op: token, fill: fill, points: path})
+			path = [][2]float64{}
+		}
+		operands = operands[:0]
+	}
+	return shapes
+}
+
+// chartTable is the data of a chart of sales and costs for three months.
+var chartTable = Table{Data: []string{
+	`{"month":"Jan","sales":10,"costs":5}`,
+	`{"month":"Feb","sales":20,"costs":5}`,
+	`{"month":"Mar","sales":40,"costs":5}`,
+}}
+
+const chartSeries = `[{"name": "Sales", "formula": {"var": "sales"}, "color": "#ff0000"}, {"name": "Costs", "formula": {"var": "costs"}, "color": "#0000ff"}]`
+
+// chartLogic draws a chart of chartTable of type with extra properties in the box from 10, 20 to 130, 100.
+func chartLogic(chartType string, properties string) string {
+	return `[{"new": {}}, {"setfont": {"size": 10}}, {"addpage": {}}, {"chart": {"type": "` + chartType + `", "labels": {"var": "month"}, "series": ` + chartSeries + `,
+		"x": 10, "y": 20, "width": 120, "height": 80` + properties + `}}]`
+}
+
+// filled returns the shapes painted with op in the fill colour of hex.
+func filled(shapes []pageShape, op string, hex string) (found []pageShape) {
+	r, g, b, _ := ParseColor(hex)
+	fill := fmt.Sprint(float64(r)/255, float64(g)/255, float64(b)/255)
+	for _, shape := range shapes {
+		if shape.op == op && shape.fill == fill {
+			found = append(found, shape)
+		}
+	}
+	return found
+}
+
+func TestChartBar(t *testing.T) {
+	shapes := renderShapes(t, chartLogic("bar", `, "legend": "none"`), []Table{chartTable})
+	sales, costs := filled(shapes, "f", "#ff0000"), filled(shapes, "f", "#0000ff")
+	if len(sales) != 3 || len(costs) != 3 {
+		t.Fatalf("bar chart should draw a bar for each month of each series, got %d and %d", len(sales), len(costs))
+	}
+
+	unit := (sales[0].points[1][1] - sales[0].points[0][1]) / 10
+	for month, value := range []float64{10, 20, 40} {
+		bar, cost := sales[month].points, costs[month].points
+		if math.Abs(bar[1][1]-bar[0][1]-value*unit) > 0.05 || math.Abs(cost[1][1]-cost[0][1]-5*unit) > 0.05 {
+			t.Fatalf("bars of month %d should be as tall as their values, got %v and %v", month+1, bar, cost)
+		}
+		if math.Abs(bar[1][1]-sales[0].points[1][1]) > 0.01 || math.Abs(cost[1][1]-bar[1][1]) > 0.01 {
+			t.Fatalf("bars of month %d should stand on the axis, got %v and %v", month+1, bar, cost)
+		}
+		if math.Abs(cost[0][0]-bar[1][0]) > 0.01 || bar[0][0] < 10 || cost[1][0] > 130 || bar[0][1] < 20 || bar[1][1] > 100 {
+			t.Fatalf("bars of month %d should be side by side within the box, got %v and %v", month+1, bar, cost)
+		}
+	}
+
+	labels := make([]string, 0)
+	for _, text := range renderPages(t, chartLogic("bar", `, "legend": "none"`), []Table{chartTable})[0] {
+		if text.y > sales[0].points[1][1] && text.text[0] >= 'A' {
+			labels = append(labels, text.text)
+		}
+	}
+	if strings.Join(labels, ",") != "Jan,Feb,Mar" {
+		t.Fatalf("bar chart should label the months below the axis, got %v", labels)
+	}
+}
+
+func TestChartStackedBar(t *testing.T) {
+	shapes := renderShapes(t, chartLogic("stackedbar", `, "legend": "none"`), []Table{chartTable})
+	sales, costs := filled(shapes, "f", "#ff0000"), filled(shapes, "f", "#0000ff")
+	if len(sales) != 3 || len(costs) != 3 {
+		t.Fatalf("stacked bar chart should draw a bar for each month of each series, got %d and %d", len(sales), len(costs))
+	}
+	for month := range sales {
+		if math.Abs(costs[month].points[1][1]-sales[month].points[0][1]) > 0.01 {
+			t.Fatalf("costs of month %d should be stacked on its sales, got %v and %v", month+1, sales[month].points, costs[month].points)
+		}
+		if math.Abs(costs[month].points[0][0]-sales[month].points[0][0]) > 0.01 {
+			t.Fatalf("stacked bars of month %d should share their x, got %v and %v", month+1, sales[month].points, costs[month].points)
+		}
+	}
+}
+
+func TestChartLine(t *testing.T) {
+	lines := filled(renderShapes(t, chartLogic("line", `, "legend": "none"`), []Table{chartTable}), "S", "#ff0000")
+	if len(lines) != 1 || len(lines[0].points) != 3 {
+		t.Fatalf("line chart should draw one line through the sales of each month, got %v", lines)
+	}
+
+	points := lines[0].points
+	if math.Abs((points[1][0]-points[0][0])-(points[2][0]-points[1][0])) > 0.01 || points[0][0] < 10 || points[2][0] > 130 {
+		t.Fatalf("line chart points should be evenly spaced within the box, got %v", points)
+	}
+	if math.Abs(2*(points[0][1]-points[1][1])-(points[1][1]-points[2][1])) > 0.05 || points[2][1] < 20 {
+		t.Fatalf("line chart points should rise with the sales of 10, 20 and 40, got %v", points)
+	}
+}
+
+func TestChartPie(t *testing.T) {
+	logic := chartLogic("pie", `, "palette": ["#ff0000", "#00ff00", "#0000ff"], "values": true`)
+	table := Table{Data: []string{`{"sales":1}`, `{"sales":1}`, `{"sales":2}`}}
+	shapes := renderShapes(t, logic, []Table{table})
+
+	end := 0.0
+	for index, color := range []string{"#ff0000", "#00ff00", "#0000ff"} {
+		slices := filled(shapes, "B", color)
+		if len(slices) != 1 {
+			t.Fatalf("pie chart should draw one slice in %s, got %d", color, len(slices))
+		}
+		points := slices[0].points
+		centre, last := points[0], points[len(points)-1]
+		angle := math.Atan2(last[0]-centre[0], centre[1]-last[1]) * 180 / math.Pi
+		end += []float64{90, 90, 180}[index]
+		if math.Abs(math.Mod(angle-end+540, 360)-180) > 0.5 {
+			t.Fatalf("slice %d should end %.0f degrees clockwise from the top, got %.1f", index+1, end, angle)
+		}
+	}
+
+	labels := make([]string, 0)
+	for _, text := range renderPages(t, logic, []Table{table})[0] {
+		if strings.HasSuffix(text.text, "%") {
+			labels = append(labels, text.text)
+		}
+	}
+	if strings.Join(labels, ",") != "25%,25%,50%" {
+		t.Fatalf("pie chart should label each slice with its share, got %v", labels)
+	}
+}
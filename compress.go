@@ -0,0 +1,133 @@
+package jsongofpdf
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"math"
+
+	"github.com/jung-kurt/gofpdf"
+	"golang.org/x/image/draw"
+)
+
+// preparedImage is image data converted and compressed ready to register with gofpdf, kept by content hash so the same
+// image registered under several names is only processed once and gofpdf embeds it once.
+type preparedImage struct {
+	data      []byte
+	imageType string
+	dpi       float64
+}
+
+// imageKey identifies the data of a prepared image by its content hash and, when images are downsampled, the size it is
+// placed at.
+type imageKey struct {
+	hash   [sha1.Size]byte
+	width  float64
+	height float64
+}
+
+// prepareImage converts data to a format gofpdf embeds and compresses it for a placed size of width by height document
+// units, where 0 means the size is not known.
+func (p *JSONGOFPDF) prepareImage(pdf *gofpdf.Fpdf, data []byte, width float64, height float64) (prepared preparedImage, err error) {
+	// Resolution is read before conversion as re-encoding drops it
+	prepared.dpi = float64(p.DPI)
+	if meta, err := GetImageMetaBytes(data); err == nil && meta.DPIX > 0 {
+		prepared.dpi = meta.DPIX
+	}
+
+	prepared.data, prepared.imageType, err = ConvertImage(data)
+	if err != nil {
+		return prepared, err
+	}
+
+	return p.CompressImage(pdf, prepared, width, height)
+}
+
+// CompressImage downsamples images with more than ImageMaxDPI pixels per inch at their placed size and re-encodes JPEGs at
+// ImageQuality. The placed size is width by height document units, or the natural size of the image when both are 0.
+// Recompressed images that come out larger than the original are discarded.
+func (p *JSONGOFPDF) CompressImage(pdf *gofpdf.Fpdf, prepared preparedImage, width float64, height float64) (preparedImage, error) {
+	if (p.ImageMaxDPI <= 0 && p.ImageQuality <= 0) || prepared.imageType == "gif" {
+		return prepared, nil
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(prepared.data))
+	if err != nil || config.Width == 0 || config.Height == 0 {
+		return prepared, err
+	}
+	pixelWidth, pixelHeight := float64(config.Width), float64(config.Height)
+
+	// Placed size in inches
+	placedWidth, placedHeight := width*pdf.GetConversionRatio()/72, height*pdf.GetConversionRatio()/72
+	if placedWidth <= 0 && placedHeight <= 0 && prepared.dpi > 0 {
+		placedWidth, placedHeight = pixelWidth/prepared.dpi, pixelHeight/prepared.dpi
+	}
+
+	scale := 1.0
+	if p.ImageMaxDPI > 0 {
+		// Keep enough pixels for the larger of the placed dimensions so cover and fill stay sharp
+		required := 0.0
+		if placedWidth > 0 {
+			required = math.Max(required, placedWidth*p.ImageMaxDPI/pixelWidth)
+		}
+		if placedHeight > 0 {
+			required = math.Max(required, placedHeight*p.ImageMaxDPI/pixelHeight)
+		}
+		if required > 0 && required < 1 {
+			scale = required
+		}
+	}
+	if scale >= 1 && (prepared.imageType != "jpg" || p.ImageQuality <= 0) {
+		return prepared, nil
+	}
+
+	var img image.Image
+	if prepared.imageType == "jpg" {
+		img, err = jpeg.Decode(bytes.NewReader(prepared.data))
+	} else {
+		img, err = png.Decode(bytes.NewReader(prepared.data))
+	}
+	if err != nil {
+		return prepared, err
+	}
+
+	if scale < 1 {
+		resizedWidth := int(math.Max(1, math.Round(pixelWidth*scale)))
+		resizedHeight := int(math.Max(1, math.Round(pixelHeight*scale)))
+		resized := image.NewNRGBA(image.Rect(0, 0, resizedWidth, resizedHeight))
+		draw.BiLinear.Scale(resized, resized.Bounds(), img, img.Bounds(), draw.Src, nil)
+		img = resized
+	}
+
+	quality := p.ImageQuality
+	if quality <= 0 {
+		quality = convertJPEGQuality
+	}
+	data, imageType, err := encodeImage(img, prepared.imageType, quality)
+	if err != nil {
+		return prepared, err
+	}
+	if scale >= 1 && len(data) >= len(prepared.data) {
+		return prepared, nil
+	}
+
+	// The natural size stays the same with fewer pixels per inch
+	return preparedImage{
+		data:      data,
+		imageType: imageType,
+		dpi:       prepared.dpi * float64(img.Bounds().Dx()) / pixelWidth,
+	}, nil
+}
+
+// SetImageCompression sets how images are compressed before they are embedded. Pass in "maxdpi" float to downsample images
+// with a higher resolution at their placed size and "quality" int from 1 to 100 to re-encode JPEGs. Images are processed
+// once for each size they are placed at and reused after, 0 turns each option off.
+// Defaults are "maxdpi": 0.0, "quality": 0
+func (p *JSONGOFPDF) SetImageCompression(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
+	p.ImageMaxDPI = p.GetFloat("maxdpi", logic, 0.0)
+	p.ImageQuality = p.GetInt("quality", logic, 0)
+	return pdf
+}
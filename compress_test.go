@@ -0,0 +1,48 @@
+package jsongofpdf
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+func TestRegisterImagePlacedSizes(t *testing.T) {
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, image.NewNRGBA(image.Rect(0, 0, 400, 200))); err != nil {
+		t.Fatal(err)
+	}
+	data := buffer.Bytes()
+
+	p := &JSONGOFPDF{DPI: 72, ImageMaxDPI: 72}
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	tests := []struct {
+		width  float64
+		pixels int
+	}{
+		{10, 28},
+		{100, 283},
+		{10, 28},
+	}
+
+	for _, test := range tests {
+		name := p.placedImageName("logo", test.width, 0)
+		if _, err := p.RegisterImage(pdf, name, data, test.width, 0); err != nil {
+			t.Fatal(err)
+		}
+		prepared := p.imageCache[imageKey{hash: sha1.Sum(data), width: test.width}]
+		config, _, err := image.DecodeConfig(bytes.NewReader(prepared.data))
+		if err != nil || config.Width != test.pixels {
+			t.Fatalf("an image placed %vmm wide should be %d pixels wide, got %d %v", test.width, test.pixels, config.Width, err)
+		}
+		if info := pdf.GetImageInfo(name); info == nil || info.Width() != pdf.GetImageInfo(p.placedImageName("logo", 10, 0)).Width() {
+			t.Fatalf("an image placed %vmm wide should keep its natural size", test.width)
+		}
+	}
+	if len(p.imageCache) != 2 {
+		t.Fatalf("the image should be processed once for each size, got %d", len(p.imageCache))
+	}
+}
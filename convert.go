@@ -0,0 +1,203 @@
+package jsongofpdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+
+	"github.com/h2non/filetype"
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/tiff"
+	"golang.org/x/image/webp"
+)
+
+// convertJPEGQuality is the quality JPEGs are encoded at when they have to be re-encoded.
+const convertJPEGQuality = 90
+
+// ConvertImage returns data in a format gofpdf can embed along with its image type. JPEGs are turned upright by their EXIF
+// orientation, 16 bit and interlaced PNGs are reduced to plain 8 bit PNGs and BMP, TIFF and WebP images are decoded with
+// pure Go decoders and encoded as PNG, or as JPEG for opaque WebP photos.
+func ConvertImage(data []byte) (converted []byte, imageType string, err error) {
+	kind, err := filetype.Match(data)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch kind.Extension {
+	case "jpg":
+		orientation := GetJpgOrientation(data)
+		if orientation <= 1 || orientation > 8 {
+			return data, "jpg", nil
+		}
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, "", err
+		}
+		return encodeImage(OrientImage(img, orientation), "jpg", convertJPEGQuality)
+	case "png":
+		// gofpdf only embeds 8 bit non interlaced PNGs; the bit depth and interlace method follow the IHDR size
+		if len(data) > 28 && data[24] <= 8 && data[28] == 0 {
+			return data, "png", nil
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, "", err
+		}
+		return encodeImage(img, "png", convertJPEGQuality)
+	case "gif":
+		return data, "gif", nil
+	case "bmp":
+		img, err := bmp.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, "", err
+		}
+		return encodeImage(img, "png", convertJPEGQuality)
+	case "tif":
+		img, err := tiff.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, "", err
+		}
+		return encodeImage(img, "png", convertJPEGQuality)
+	case "webp":
+		img, err := webp.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, "", err
+		}
+		if opaque, ok := img.(interface{ Opaque() bool }); ok && opaque.Opaque() {
+			return encodeImage(img, "jpg", convertJPEGQuality)
+		}
+		return encodeImage(img, "png", convertJPEGQuality)
+	}
+
+	return nil, "", ErrImageType
+}
+
+// encodeImage encodes img as an 8 bit "png" or a "jpg" of quality.
+func encodeImage(img image.Image, imageType string, quality int) (data []byte, encodedType string, err error) {
+	buffer := new(bytes.Buffer)
+	if imageType == "jpg" {
+		err = jpeg.Encode(buffer, img, &jpeg.Options{Quality: quality})
+		return buffer.Bytes(), imageType, err
+	}
+
+	// Drawing onto NRGBA reduces 16 bit colour and gray images to 8 bits per channel
+	bounds := img.Bounds()
+	nrgba := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(nrgba, nrgba.Bounds(), img, bounds.Min, draw.Src)
+	if nrgba.Opaque() {
+		rgb := image.NewRGBA(nrgba.Bounds())
+		draw.Draw(rgb, rgb.Bounds(), nrgba, image.Point{}, draw.Src)
+		err = png.Encode(buffer, rgb)
+	} else {
+		err = png.Encode(buffer, nrgba)
+	}
+	return buffer.Bytes(), "png", err
+}
+
+// GetJpgOrientation returns the EXIF orientation tag of JPEG data, 1 to 8, or 0 when there is none.
+func GetJpgOrientation(data []byte) int {
+	position := 2
+	for position+4 <= len(data) {
+		if data[position] != 0xFF {
+			return 0
+		}
+		marker := data[position+1]
+		length := int(data[position+2])<<8 | int(data[position+3])
+		// The image data follows the start of scan so no metadata remains
+		if marker == 0xDA || length < 2 {
+			return 0
+		}
+		segment := data[position+4 : minInt(position+2+length, len(data))]
+		if marker == 0xE1 && len(segment) > 14 && string(segment[:6]) == "Exif\x00\x00" {
+			return exifOrientation(segment[6:])
+		}
+		position += 2 + length
+	}
+	return 0
+}
+
+// exifOrientation reads the orientation tag from the first IFD of a TIFF structured EXIF block.
+func exifOrientation(tiffData []byte) int {
+	var order binary.ByteOrder
+	switch string(tiffData[:2]) {
+	case "II":
+		order = binary.LittleEndian
+		break
+	case "MM":
+		order = binary.BigEndian
+		break
+	default:
+		return 0
+	}
+
+	offset := int(order.Uint32(tiffData[4:8]))
+	if offset+2 > len(tiffData) {
+		return 0
+	}
+	entries := int(order.Uint16(tiffData[offset : offset+2]))
+	for i := 0; i < entries; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiffData) {
+			return 0
+		}
+		if order.Uint16(tiffData[entry:entry+2]) == 0x0112 {
+			return int(order.Uint16(tiffData[entry+8 : entry+10]))
+		}
+	}
+	return 0
+}
+
+// OrientImage flips and rotates img so an image stored with EXIF orientation is displayed upright.
+func OrientImage(img image.Image, orientation int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	source := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(source, source.Bounds(), img, bounds.Min, draw.Src)
+
+	resultWidth, resultHeight := width, height
+	if orientation >= 5 {
+		resultWidth, resultHeight = height, width
+	}
+	result := image.NewRGBA(image.Rect(0, 0, resultWidth, resultHeight))
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			targetX, targetY := x, y
+			switch orientation {
+			case 2:
+				targetX = width - 1 - x
+				break
+			case 3:
+				targetX, targetY = width-1-x, height-1-y
+				break
+			case 4:
+				targetY = height - 1 - y
+				break
+			case 5:
+				targetX, targetY = y, x
+				break
+			case 6:
+				targetX, targetY = height-1-y, x
+				break
+			case 7:
+				targetX, targetY = height-1-y, width-1-x
+				break
+			case 8:
+				targetX, targetY = y, width-1-x
+				break
+			}
+			copy(result.Pix[result.PixOffset(targetX, targetY):][:4], source.Pix[source.PixOffset(x, y):][:4])
+		}
+	}
+	return result
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -15,12 +15,24 @@ type JSONGOFPDFOptions struct {
 	Data    string
 	Tables  []Table
 	Globals map[string]interface{}
+	// Images are made available to the image operations by name
+	Images        map[string][]byte
+	ImageProvider ImageProvider
+	// DPI is the resolution of images that do not record their own, 96 when not set
+	DPI int
+}
+
+// ImageProvider resolves image names that are not in Images, for example from a blob store or database.
+// Return ErrImageNotFound to fall back to loading the name as a file path.
+type ImageProvider interface {
+	GetImage(name string) (data []byte, err error)
 }
 
 type JSONGOFPDF struct {
 	Globals  map[string]interface{}
 	tr       func(string) string
 	Logic    string
+	Data     string
 	DocWidth float64
 	initY    float64
 
@@ -32,6 +44,27 @@ type JSONGOFPDF struct {
 	RowIndex     int
 	RowHeight    float64
 	RowCells     float64
+	// Section options
+	headerLogic      string
+	footerLogic      string
+	tableHeader      string
+	tablePageHeader  string
+	tablePageFooter  string
+	inTableSection   bool
+	inTableBody      bool
+	renderedData     []string
+	pageRenderedData []string
+	groups           []TableGroup
+	groupKeys        [][]interface{}
+	currentGroupData []string
+	tableLayout      *tableLayout
+	// Page break options
+	keepTogether      bool
+	allowSplit        bool
+	rowSplit          *rowSplit
+	pageTop           float64
+	pageTops          map[int]float64
+	tableHeaderHeight float64
 	// Cell options
 	CellPreIndex int
 	CellIndex    int
@@ -41,9 +74,19 @@ type JSONGOFPDF struct {
 	CurrentRowY  float64
 	NextY        float64
 	ManualY      float64
+	// Font options
+	FontFamily  string
+	FontStyle   string
+	Hyphenation string
 	// Media options
-	MediaIndex int
-	DPI        int
+	MediaIndex    int
+	DPI           int
+	Images        map[string][]byte
+	ImageProvider ImageProvider
+	ImageMaxDPI   float64
+	ImageQuality  int
+	imageCache    map[imageKey]preparedImage
+	svgs          map[string]*SVG
 }
 
 type Table struct {
@@ -64,6 +107,8 @@ type Cell struct {
 	Images   []ImageFile
 	Disabled bool
 	Type     string
+	Colspan  int
+	Rowspan  int
 }
 
 type ImageFile struct {
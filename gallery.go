@@ -0,0 +1,235 @@
+package jsongofpdf
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"math"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/vjeantet/jodaTime"
+)
+
+// Gallery lays cell images out in a grid of Columns within the cell width, separated by Gap. Images are scaled to the
+// column width and no taller than MaxHeight when it is set, each grid row taking the height of its tallest image. Caption
+// "name", "created" or "both" writes the image name and or created date formatted by DateFormat under each image in lines
+// of LineHeight.
+type Gallery struct {
+	Columns    int
+	Gap        float64
+	MaxHeight  float64
+	Caption    string
+	DateFormat string
+	LineHeight float64
+	Fit        string
+	Align      string
+	VAlign     string
+}
+
+// galleryImage is a cell image loaded for a gallery with its natural size in document units and caption lines.
+type galleryImage struct {
+	name     string
+	svg      *SVG
+	width    float64
+	height   float64
+	captions []string
+}
+
+// GetGallery reads the gallery properties of a multi cell, returning nil when "imagecolumns" is not set so images stack.
+func (p *JSONGOFPDF) GetGallery(pdf *gofpdf.Fpdf, logic string) *Gallery {
+	columns := p.GetInt("imagecolumns", logic, 0)
+	if columns <= 0 {
+		return nil
+	}
+
+	lineHeight := p.GetFloat("height", logic, 0.0)
+	if lineHeight <= 0 {
+		_, lineHeight = pdf.GetFontSize()
+	}
+	return &Gallery{
+		Columns:    columns,
+		Gap:        p.GetFloat("imagegap", logic, 2.0),
+		MaxHeight:  p.GetFloat("imagemaxheight", logic, 0.0),
+		Caption:    p.GetString("imagecaption", logic, ""),
+		DateFormat: p.GetString("imagedateformat", logic, "d/M/yyyy"),
+		LineHeight: lineHeight,
+		Fit:        p.GetString("imagefit", logic, "contain"),
+		Align:      p.GetString("imagealign", logic, "C"),
+		VAlign:     p.GetString("imagevalign", logic, "M"),
+	}
+}
+
+// cellImageName is the name a cell image is registered under, made from its content so the same image is registered once
+// however often it is rendered or measured, and images sharing a file name are never taken for one another.
+func cellImageName(image ImageFile) string {
+	hash := sha1.Sum([]byte(image.Data))
+	return "media" + hex.EncodeToString(hash[:])
+}
+
+// RegisterCellImage decodes and registers a cell image, hex encoded as stored by MSSQL or a data uri or base64, under name.
+// SVG images are parsed and returned instead of registered with gofpdf. width and height are the placed size passed to
+// RegisterImage.
+func (p *JSONGOFPDF) RegisterCellImage(pdf *gofpdf.Fpdf, name string, image ImageFile, width float64, height float64) (info *gofpdf.ImageInfoType, svg *SVG, err error) {
+	if svg = p.svgs[name]; svg != nil {
+		return nil, svg, nil
+	}
+	if info = pdf.GetImageInfo(name); info != nil {
+		return info, nil, nil
+	}
+
+	data, err := hex.DecodeString(strings.TrimPrefix(image.Data, "0x"))
+	if err != nil {
+		data, err = DecodeImageData(image.Data)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if IsSVG(data) {
+		svg, err = p.RegisterSVG(name, data)
+		return nil, svg, err
+	}
+	info, err = p.RegisterImage(pdf, name, data, width, height)
+	return info, nil, err
+}
+
+// columnWidth is the width of each gallery column in a cell of width.
+func (g *Gallery) columnWidth(width float64) float64 {
+	return math.Max(0, (width-g.Gap*float64(g.Columns-1))/float64(g.Columns))
+}
+
+// galleryImages loads the images of a gallery skipping those that cannot be decoded.
+func (p *JSONGOFPDF) galleryImages(pdf *gofpdf.Fpdf, images []ImageFile, width float64, gallery *Gallery) []galleryImage {
+	columnWidth := gallery.columnWidth(width)
+	items := make([]galleryImage, 0, len(images))
+	for _, image := range images {
+		name := p.placedImageName(cellImageName(image), columnWidth, gallery.MaxHeight)
+		info, svg, err := p.RegisterCellImage(pdf, name, image, columnWidth, gallery.MaxHeight)
+		if err != nil {
+			continue
+		}
+
+		item := galleryImage{name: name, svg: svg}
+		if svg != nil {
+			unit := 72.0 / 96.0 / pdf.GetConversionRatio()
+			item.width, item.height = svg.Width*unit, svg.Height*unit
+		} else {
+			item.width, item.height = info.Extent()
+		}
+		if item.width <= 0 || item.height <= 0 {
+			continue
+		}
+
+		created := ""
+		if !image.Created.IsZero() {
+			created = jodaTime.Format(gallery.DateFormat, image.Created)
+		}
+		switch gallery.Caption {
+		case "name":
+			item.captions = []string{image.Name}
+			break
+		case "created":
+			item.captions = []string{created}
+			break
+		case "both":
+			item.captions = []string{image.Name, created}
+			break
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// galleryRowHeights returns the image height and caption height of each grid row of items.
+func (g *Gallery) galleryRowHeights(items []galleryImage, width float64) (imageHeights []float64, captionHeights []float64) {
+	columnWidth := g.columnWidth(width)
+	for start := 0; start < len(items); start += g.Columns {
+		imageHeight, captionLines := 0.0, 0
+		for _, item := range items[start:minInt(start+g.Columns, len(items))] {
+			height := columnWidth * item.height / item.width
+			if g.MaxHeight > 0 && height > g.MaxHeight {
+				height = g.MaxHeight
+			}
+			imageHeight = math.Max(imageHeight, height)
+			if len(item.captions) > captionLines {
+				captionLines = len(item.captions)
+			}
+		}
+		imageHeights = append(imageHeights, imageHeight)
+		captionHeights = append(captionHeights, float64(captionLines)*g.LineHeight)
+	}
+	return imageHeights, captionHeights
+}
+
+// GalleryHeight measures the height of images laid out as a gallery in a cell of width, used by PreRowMultiCell to size
+// the row before it is rendered.
+func (p *JSONGOFPDF) GalleryHeight(pdf *gofpdf.Fpdf, images []ImageFile, width float64, gallery *Gallery) float64 {
+	imageHeights, captionHeights := gallery.galleryRowHeights(p.galleryImages(pdf, images, width, gallery), width)
+	height := 0.0
+	for index := range imageHeights {
+		if index > 0 {
+			height += gallery.Gap
+		}
+		height += imageHeights[index] + captionHeights[index]
+	}
+	return height
+}
+
+// DrawGallery draws images as a gallery at x and the current y in a cell of width. Grid rows that do not fit above the
+// page break margin move to the next page so an image is never split, the left and right border and fill of the cell
+// are continued beside each grid row. The current y is left below the gallery.
+func (p *JSONGOFPDF) DrawGallery(pdf *gofpdf.Fpdf, images []ImageFile, x float64, width float64, gallery *Gallery, border string, fill bool) {
+	items := p.galleryImages(pdf, images, width, gallery)
+	imageHeights, captionHeights := gallery.galleryRowHeights(items, width)
+	columnWidth := gallery.columnWidth(width)
+
+	sideBorder := ""
+	if border == "1" {
+		sideBorder = "LR"
+	} else {
+		for _, side := range "LR" {
+			if strings.ContainsRune(strings.ToUpper(border), side) {
+				sideBorder += string(side)
+			}
+		}
+	}
+	captionAlign := "L"
+	switch strings.ToUpper(gallery.Align) {
+	case "C", "CENTER":
+		captionAlign = "C"
+		break
+	case "R", "RIGHT":
+		captionAlign = "R"
+		break
+	}
+
+	for row, imageHeight := range imageHeights {
+		rowHeight := imageHeight + captionHeights[row]
+		if row < len(imageHeights)-1 {
+			rowHeight += gallery.Gap
+		}
+		pdf.SetX(x)
+		p.PageBreak(pdf, imageHeight+captionHeights[row])
+		y := pdf.GetY()
+		if sideBorder != "" || fill {
+			pdf.SetXY(x, y)
+			pdf.CellFormat(width, rowHeight, "", sideBorder, 0, "", fill, 0, "")
+		}
+
+		start := row * gallery.Columns
+		for column, item := range items[start:minInt(start+gallery.Columns, len(items))] {
+			itemX := x + float64(column)*(columnWidth+gallery.Gap)
+			if item.svg != nil {
+				p.DrawSVG(pdf, item.svg, itemX, y, columnWidth, imageHeight, gallery.Fit, gallery.Align, gallery.VAlign, "", "")
+			} else {
+				p.FitImage(pdf, item.name, itemX, y, columnWidth, imageHeight, gallery.Fit, gallery.Align, gallery.VAlign, 0, "")
+			}
+			for line, caption := range item.captions {
+				if lines := p.SplitText(pdf, p.tr(caption), columnWidth, &TextStyle{}); len(lines) > 0 {
+					pdf.SetXY(itemX, y+imageHeight+float64(line)*gallery.LineHeight)
+					pdf.CellFormat(columnWidth, gallery.LineHeight, lines[0], "", 0, captionAlign, false, 0, "")
+				}
+			}
+		}
+		pdf.SetXY(x, y+rowHeight)
+	}
+}
@@ -0,0 +1,146 @@
+package jsongofpdf
+
+import (
+	"sort"
+	"strings"
+
+	jsonlogic "github.com/GeorgeD19/json-logic-go"
+	"github.com/buger/jsonparser"
+	"github.com/spf13/cast"
+)
+
+// TableGroup is a level of grouping of table rows. Rows are grouped by the result of the json-logic Key over their Data,
+// with the Header operations rendered before and the Footer operations after the rows of each group.
+type TableGroup struct {
+	Key    string
+	Header string
+	Footer string
+}
+
+// GetTableGroups reads the "groups" array of a tablefunc, outermost level first. Each group takes a "key" as json-logic or
+// as a dotted path into the row data, and "header" and "footer" arrays of operations.
+func (p *JSONGOFPDF) GetTableGroups(logic string) (groups []TableGroup) {
+	jsonparser.ArrayEach([]byte(p.GetString("groups", logic, "")), func(value []byte, dataType jsonparser.ValueType, offset int, err error) {
+		switch dataType {
+		case jsonparser.Object:
+			key := p.GetString("key", string(value), "")
+			if key == "" {
+				break
+			}
+			groups = append(groups, TableGroup{
+				Key:    logicKey(key),
+				Header: p.GetString("header", string(value), ""),
+				Footer: p.GetString("footer", string(value), ""),
+			})
+			break
+		}
+	})
+	return groups
+}
+
+// GroupTable returns a copy of the table with the rows, and their data, ordered so the rows of each group are together.
+// Groups are ordered by their keys and rows keep their order within a group. The keys of each row are returned by level.
+func GroupTable(table Table, groups []TableGroup) (grouped Table, keys [][]interface{}) {
+	rowKeys := make([][]interface{}, len(table.Rows))
+	order := make([]int, len(table.Rows))
+	for index := range table.Rows {
+		order[index] = index
+		data := ""
+		if len(table.Data) > index {
+			data = table.Data[index]
+		}
+		for _, group := range groups {
+			key, _ := jsonlogic.Apply(group.Key, data)
+			rowKeys[index] = append(rowKeys[index], key)
+		}
+	}
+
+	sort.SliceStable(order, func(a, b int) bool {
+		for level := range groups {
+			if result := CompareValues(rowKeys[order[a]][level], rowKeys[order[b]][level]); result != 0 {
+				return result < 0
+			}
+		}
+		return false
+	})
+
+	return reorderTable(table, order), reorderKeys(rowKeys, order)
+}
+
+// reorderTable returns a copy of the table with the rows, and their data when present, in the given order. Rows without
+// data are given empty data so the data stays aligned with the rows.
+func reorderTable(table Table, order []int) (ordered Table) {
+	for _, index := range order {
+		ordered.Rows = append(ordered.Rows, table.Rows[index])
+		if len(table.Data) == 0 {
+			continue
+		}
+		data := ""
+		if len(table.Data) > index {
+			data = table.Data[index]
+		}
+		ordered.Data = append(ordered.Data, data)
+	}
+	return ordered
+}
+
+func reorderKeys(keys [][]interface{}, order []int) (ordered [][]interface{}) {
+	for _, index := range order {
+		ordered = append(ordered, keys[index])
+	}
+	return ordered
+}
+
+// CompareValues orders two values numerically when both are numbers and as strings otherwise, returning -1, 0 or 1.
+func CompareValues(a interface{}, b interface{}) int {
+	numberA, errA := cast.ToFloat64E(a)
+	numberB, errB := cast.ToFloat64E(b)
+	if errA == nil && errB == nil && a != nil && b != nil {
+		if numberA < numberB {
+			return -1
+		} else if numberA > numberB {
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(cast.ToString(a), cast.ToString(b))
+}
+
+// groupLevel returns the outermost group level that changes between the previous row and this one, or the number of
+// levels when the row is in the same groups as the previous row.
+func (p *JSONGOFPDF) groupLevel(row int) int {
+	if row == 0 {
+		return 0
+	}
+	for level := range p.groups {
+		if CompareValues(p.groupKeys[row-1][level], p.groupKeys[row][level]) != 0 {
+			return level
+		}
+	}
+	return len(p.groups)
+}
+
+// groupData returns the data of the rows in the same group as the row at the given level.
+func (p *JSONGOFPDF) groupData(level int, row int) (data []string) {
+	table := p.Tables[p.TableIndex]
+	start, end := row, row
+	for start > 0 && p.sameGroup(level, start-1, row) {
+		start--
+	}
+	for end < len(table.Rows)-1 && p.sameGroup(level, end+1, row) {
+		end++
+	}
+	for index := start; index <= end && index < len(table.Data); index++ {
+		data = append(data, table.Data[index])
+	}
+	return data
+}
+
+func (p *JSONGOFPDF) sameGroup(level int, a int, b int) bool {
+	for index := 0; index <= level; index++ {
+		if CompareValues(p.groupKeys[a][index], p.groupKeys[b][index]) != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package jsongofpdf
+
+import "testing"
+
+func TestCompareValues(t *testing.T) {
+	tests := []struct {
+		a, b   interface{}
+		result int
+	}{
+		{2, 10, -1},
+		{"2", "10", -1},
+		{10.5, 10.5, 0},
+		{"b", "a", 1},
+		{"North", "South", -1},
+		{nil, "a", -1},
+	}
+	for _, test := range tests {
+		if result := CompareValues(test.a, test.b); result != test.result {
+			t.Fatalf("CompareValues(%v, %v) = %d, want %d", test.a, test.b, result, test.result)
+		}
+	}
+}
+
+func TestGroupTable(t *testing.T) {
+	table := Table{}
+	for _, data := range []string{
+		`{"region":"South","invoice":"1"}`,
+		`{"region":"North","invoice":"2"}`,
+		`{"region":"South","invoice":"3"}`,
+		`{"region":"North","invoice":"4"}`,
+	} {
+		table.Rows = append(table.Rows, Row{Cells: []Cell{{Key: "data", Value: data}}})
+		table.Data = append(table.Data, data)
+	}
+
+	grouped, keys := GroupTable(table, []TableGroup{{Key: `{"var":"region"}`}})
+	want := []string{"2", "4", "1", "3"}
+	for index, data := range grouped.Data {
+		if grouped.Rows[index].Cells[0].Value != data {
+			t.Fatalf("row %d is not aligned with its data", index)
+		}
+		if data[len(data)-3:len(data)-2] != want[index] {
+			t.Fatalf("row %d is %s, want invoice %s", index, data, want[index])
+		}
+	}
+	if keys[0][0] != "North" || keys[3][0] != "South" {
+		t.Fatalf("keys = %v", keys)
+	}
+}
+
+func TestGroupTableMissingData(t *testing.T) {
+	table := Table{Data: []string{`{"region":"South"}`}}
+	for _, value := range []string{"South", "North", "West"} {
+		table.Rows = append(table.Rows, Row{Cells: []Cell{{Key: "region", Value: value}}})
+	}
+
+	grouped, _ := GroupTable(table, []TableGroup{{Key: `{"var":"region"}`}})
+	if len(grouped.Data) != len(grouped.Rows) {
+		t.Fatalf("data should stay aligned with the rows, got %d rows and %d data", len(grouped.Rows), len(grouped.Data))
+	}
+	for index, row := range grouped.Rows {
+		if (row.Cells[0].Value == "South") != (grouped.Data[index] != "") {
+			t.Fatalf("row %d %v has data %q", index, row.Cells[0].Value, grouped.Data[index])
+		}
+	}
+}
@@ -3,10 +3,12 @@ package jsongofpdf
 import (
 	"encoding/hex"
 	"io/ioutil"
-	"os"
+	"regexp"
+	"strings"
 
 	"github.com/buger/jsonparser"
 	"github.com/h2non/filetype"
+	"github.com/jung-kurt/gofpdf"
 	"github.com/spf13/cast"
 )
 
@@ -42,103 +44,224 @@ func (p *JSONGOFPDF) GetString(name string, logic string, fallback string) (valu
 	return p.GetStringIndex(name, logic, fallback)
 }
 
-// GetImage returns a File type containing the hex version of the image with associated meta information
-func GetImage(FileName string) (f ImageFile, Err error) {
+// GetCell resolves the cell an operation renders from. The current row cell is used unless a target key or path is given,
+// loop searches every table for the target, text overrides the cell value and globals matching the target win over all.
+func (p *JSONGOFPDF) GetCell(target string, text string, loop bool) (cell Cell) {
+	if len(p.Tables) > p.TableIndex {
+		if len(p.Tables[p.TableIndex].Rows) > p.RowIndex {
+			if target == "" && len(p.Tables[p.TableIndex].Rows[p.RowIndex].Cells) > p.CellIndex {
+				cell = p.Tables[p.TableIndex].Rows[p.RowIndex].Cells[p.CellIndex]
+			}
+			for _, rowCell := range p.Tables[p.TableIndex].Rows[p.RowIndex].Cells {
+				if rowCell.Key == target || rowCell.Path == target {
+					cell = rowCell
+				}
+			}
+		}
+	}
 
-	FoundFile := ImageFile{}
+	if loop {
+		for _, table := range p.Tables {
+			for _, row := range table.Rows {
+				for _, rowCell := range row.Cells {
+					if rowCell.Key == target || rowCell.Path == target {
+						cell = rowCell
+					}
+				}
+			}
+		}
+	}
 
-	// Get file contents
-	FileData, _ := ioutil.ReadFile(FileName)
+	if text != "" {
+		cell = Cell{
+			Value: text,
+		}
+	}
 
-	FileMeta, err := filetype.Match(FileData)
-	if err != nil {
-		return FoundFile, err
+	for index, value := range p.Globals {
+		if index == target {
+			cell = Cell{
+				Path:  index,
+				Key:   index,
+				Title: index,
+				Value: value,
+			}
+		}
 	}
 
-	// Compatible with MSSQL binary storage
-	FileContent := hex.EncodeToString(FileData)
-	FileContent = "0x" + FileContent
+	return cell
+}
 
-	FoundFile.Data = FileContent
-	FoundFile.Type = FileMeta.Extension
-	FoundFile.Mime = FileMeta.MIME.Value
+// saveGraphics returns a function restoring the font, colours, line width, alpha, cell margin, page breaking and position
+// changed by an operation that draws with gofpdf primitives.
+func (p *JSONGOFPDF) saveGraphics(pdf *gofpdf.Fpdf) (restore func()) {
+	fontSize, _ := pdf.GetFontSize()
+	drawR, drawG, drawB := pdf.GetDrawColor()
+	fillR, fillG, fillB := pdf.GetFillColor()
+	textR, textG, textB := pdf.GetTextColor()
+	lineWidth := pdf.GetLineWidth()
+	alpha, blendMode := pdf.GetAlpha()
+	cellMargin := pdf.GetCellMargin()
+	auto, margin := pdf.GetAutoPageBreak()
+	x, y := pdf.GetXY()
+	return func() {
+		family := p.FontFamily
+		if family == "" {
+			family = "Arial"
+		}
+		pdf.SetFont(family, p.FontStyle, fontSize)
+		pdf.SetDrawColor(drawR, drawG, drawB)
+		pdf.SetFillColor(fillR, fillG, fillB)
+		pdf.SetTextColor(textR, textG, textB)
+		pdf.SetLineWidth(lineWidth)
+		pdf.SetAlpha(alpha, blendMode)
+		pdf.SetCellMargin(cellMargin)
+		pdf.SetAutoPageBreak(auto, margin)
+		pdf.SetXY(x, y)
+	}
+}
 
-	File, err := os.Open(FileName)
-	defer File.Close()
+// GetDataString returns the value stored in the bound Data at a dotted path, for example "order.number" or "items.[0].sku".
+// Numbers and booleans are returned as their json text.
+func (p *JSONGOFPDF) GetDataString(path string) (value string, err error) {
+	attribute, dataType, _, err := jsonparser.Get([]byte(p.Data), strings.Split(path, ".")...)
 	if err != nil {
-		return FoundFile, err
+		return "", err
 	}
+	if dataType == jsonparser.String {
+		return jsonparser.ParseString(attribute)
+	}
+	return string(attribute), nil
+}
 
-	head := make([]byte, 261)
-	File.Read(head)
+// interpolationPattern matches the {key} and {path} placeholders replaced by Interpolate.
+var interpolationPattern = regexp.MustCompile(`\{([^{}]+)\}`)
 
-	// TODO Define err message
-	if filetype.IsImage(head) == false {
-		return FoundFile, err
+// Interpolate replaces globals by name and {key} placeholders with the values of the current table row as CellFormat does,
+// then {path} placeholders with the value in the bound Data at the dotted path, for example "{invoice.total}".
+func (p *JSONGOFPDF) Interpolate(text string) string {
+	if !strings.Contains(text, "{") && len(p.Globals) == 0 {
+		return text
 	}
 
-	// Only parse for supported functions
-	switch FoundFile.Type {
-	case "jpg":
-		FoundFile.Width, FoundFile.Height = GetJpgDimensions(File)
-	case "gif":
-		FoundFile.Width, FoundFile.Height = GetGifDimensions(File)
-	case "png":
-		FoundFile.Width, FoundFile.Height = GetPngDimensions(File)
-	case "bmp":
-		FoundFile.Width, FoundFile.Height = GetBmpDimensions(File)
-	default:
-		return FoundFile, err
+	for index, value := range p.Globals {
+		text = strings.Replace(text, index, cast.ToString(value), -1)
 	}
 
-	return FoundFile, nil
+	if len(p.Tables) > p.TableIndex && len(p.Tables[p.TableIndex].Rows) > p.RowIndex {
+		for _, value := range p.Tables[p.TableIndex].Rows[p.RowIndex].Cells {
+			if arrVal, ok := value.Value.(map[string]interface{}); ok {
+				for i, v := range arrVal {
+					text = strings.Replace(text, "{"+i+"}", cast.ToString(v), -1)
+				}
+			}
+		}
+	}
+
+	return interpolationPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		value, err := p.GetDataString(placeholder[1 : len(placeholder)-1])
+		if err != nil {
+			return placeholder
+		}
+		return value
+	})
 }
 
-func GetJpgDimensions(file *os.File) (width int, height int) {
-	fi, _ := file.Stat()
-	fileSize := fi.Size()
-
-	position := int64(4)
-	bytes := make([]byte, 4)
-	file.ReadAt(bytes[:2], position)
-	length := int(bytes[0]<<8) + int(bytes[1])
-	for position < fileSize {
-		position += int64(length)
-		file.ReadAt(bytes, position)
-		length = int(bytes[2])<<8 + int(bytes[3])
-		if (bytes[1] == 0xC0 || bytes[1] == 0xC2) && bytes[0] == 0xFF && length > 7 {
-			file.ReadAt(bytes, position+5)
-			width = int(bytes[2])<<8 + int(bytes[3])
-			height = int(bytes[0])<<8 + int(bytes[1])
-			return
+// ParseColor converts a "#rgb", "#rrggbb" or "rgb(r, g, b)" string or a basic colour name into red, green and blue components.
+func ParseColor(value string) (r int, g int, b int, ok bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	switch value {
+	case "black":
+		return 0, 0, 0, true
+	case "white":
+		return 255, 255, 255, true
+	case "red":
+		return 255, 0, 0, true
+	case "green":
+		return 0, 128, 0, true
+	case "blue":
+		return 0, 0, 255, true
+	case "grey", "gray":
+		return 128, 128, 128, true
+	case "silver":
+		return 192, 192, 192, true
+	case "yellow":
+		return 255, 255, 0, true
+	case "orange":
+		return 255, 165, 0, true
+	case "purple":
+		return 128, 0, 128, true
+	case "navy":
+		return 0, 0, 128, true
+	case "maroon":
+		return 128, 0, 0, true
+	case "olive":
+		return 128, 128, 0, true
+	case "teal":
+		return 0, 128, 128, true
+	case "lime":
+		return 0, 255, 0, true
+	case "aqua", "cyan":
+		return 0, 255, 255, true
+	case "fuchsia", "magenta":
+		return 255, 0, 255, true
+	}
+
+	if strings.HasPrefix(value, "#") {
+		hexValue := value[1:]
+		if len(hexValue) == 3 {
+			hexValue = string([]byte{hexValue[0], hexValue[0], hexValue[1], hexValue[1], hexValue[2], hexValue[2]})
+		}
+		decoded, err := hex.DecodeString(hexValue)
+		if err != nil || len(decoded) != 3 {
+			return 0, 0, 0, false
 		}
-		position += 2
+		return int(decoded[0]), int(decoded[1]), int(decoded[2]), true
 	}
-	return 0, 0
-}
 
-func GetGifDimensions(file *os.File) (width int, height int) {
-	bytes := make([]byte, 4)
-	file.ReadAt(bytes, 6)
-	width = int(bytes[0]) + int(bytes[1])*256
-	height = int(bytes[2]) + int(bytes[3])*256
-	return
-}
+	if strings.HasPrefix(value, "rgb(") && strings.HasSuffix(value, ")") {
+		parts := strings.Split(value[4:len(value)-1], ",")
+		if len(parts) != 3 {
+			return 0, 0, 0, false
+		}
+		return cast.ToInt(strings.TrimSpace(parts[0])), cast.ToInt(strings.TrimSpace(parts[1])), cast.ToInt(strings.TrimSpace(parts[2])), true
+	}
 
-func GetBmpDimensions(file *os.File) (width int, height int) {
-	bytes := make([]byte, 8)
-	file.ReadAt(bytes, 18)
-	width = int(bytes[3])<<24 | int(bytes[2])<<16 | int(bytes[1])<<8 | int(bytes[0])
-	height = int(bytes[7])<<24 | int(bytes[6])<<16 | int(bytes[5])<<8 | int(bytes[4])
-	return
+	return 0, 0, 0, false
 }
 
-func GetPngDimensions(file *os.File) (width int, height int) {
-	bytes := make([]byte, 8)
-	file.ReadAt(bytes, 16)
-	width = int(bytes[0])<<24 | int(bytes[1])<<16 | int(bytes[2])<<8 | int(bytes[3])
-	height = int(bytes[4])<<24 | int(bytes[5])<<16 | int(bytes[6])<<8 | int(bytes[7])
-	return
+// GetImage returns a File type containing the hex version of the image with associated meta information
+func GetImage(FileName string) (f ImageFile, Err error) {
+
+	FoundFile := ImageFile{}
+
+	// Get file contents
+	FileData, err := ioutil.ReadFile(FileName)
+	if err != nil {
+		return FoundFile, err
+	}
+
+	FileMeta, err := filetype.Match(FileData)
+	if err != nil {
+		return FoundFile, err
+	}
+
+	// Compatible with MSSQL binary storage
+	FoundFile.Data = "0x" + hex.EncodeToString(FileData)
+	FoundFile.Type = FileMeta.Extension
+	FoundFile.Mime = FileMeta.MIME.Value
+
+	// Only parse for supported functions
+	meta, err := GetImageMetaBytes(FileData)
+	if err == ErrImageType {
+		return FoundFile, nil
+	}
+	if err != nil {
+		return FoundFile, err
+	}
+	FoundFile.Width, FoundFile.Height = meta.Width, meta.Height
+
+	return FoundFile, nil
 }
 
 func (p *JSONGOFPDF) GetStringIndex(name string, logic string, fallback string) (value string) {
@@ -194,6 +317,4 @@ func (p *JSONGOFPDF) RunValue(name string, logic string) (val []byte, dataType j
 	default:
 		return nil, jsonparser.NotExist
 	}
-
-	return nil, jsonparser.NotExist
 }
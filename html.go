@@ -0,0 +1,444 @@
+package jsongofpdf
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/buger/jsonparser"
+	"github.com/jung-kurt/gofpdf"
+	"github.com/spf13/cast"
+)
+
+var (
+	htmlTagRe       = regexp.MustCompile(`<[^>]*>`)
+	htmlAttrRe      = regexp.MustCompile(`([a-zA-Z-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	htmlSpaceRe     = regexp.MustCompile(`\s+`)
+	markdownLinkRe  = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	markdownBoldRe  = regexp.MustCompile(`\*\*([^*]+)\*\*|(^|\s)__([^_]+)__`)
+	markdownItalRe  = regexp.MustCompile(`\*([^*\s][^*]*)\*|(^|\s)_([^_]+)_`)
+	markdownHeadRe  = regexp.MustCompile(`^(#{1,3})\s+(.*)$`)
+	markdownULRe    = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	markdownOLRe    = regexp.MustCompile(`^\s*\d+[.)]\s+(.*)$`)
+	htmlHeadingSize = map[string]float64{"h1": 1.6, "h2": 1.35, "h3": 1.15}
+)
+
+// HTML renders a basic subset of HTML (b, i, u, a, br, p, ul, ol, li, h1-h3 and span with color) into a box starting at the current position.
+// Pass in "width" float, "height" float (line height), "text" string, "attribute" string and "target" string object properties in json logic.
+// Defaults are "width": 0.0 (up to the right margin), "height": 5.0, "text": ""
+func (p *JSONGOFPDF) HTML(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
+	return p.renderMarkup(pdf, logic, false)
+}
+
+// Markdown renders headings, lists, links, bold and italic markdown the same way as HTML. Takes the same object properties as HTML.
+func (p *JSONGOFPDF) Markdown(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
+	return p.renderMarkup(pdf, logic, true)
+}
+
+func (p *JSONGOFPDF) renderMarkup(pdf *gofpdf.Fpdf, logic string, markdown bool) (opdf *gofpdf.Fpdf) {
+	width := p.GetFloat("width", logic, 0.0)
+	height := p.GetFloat("height", logic, 5.0)
+
+	textStyle := p.GetTextStyle(logic)
+	p.ApplyTextStyle(pdf, textStyle)
+	renderText, _ := p.markupText(logic, markdown, false)
+	p.WriteHTML(pdf, pdf.GetX(), width, height, renderText)
+	p.RestoreTextStyle(pdf, textStyle)
+
+	CurrentY := pdf.GetY()
+	if CurrentY > p.NextY {
+		p.NextY = CurrentY
+	}
+
+	return pdf
+}
+
+// markupText resolves the html of an HTML or Markdown operation from its "text", "attribute" and "target" properties. With
+// pre the cell being measured by the pre-render pipeline is read, and ok is false when the operation does not render it.
+func (p *JSONGOFPDF) markupText(logic string, markdown bool, pre bool) (renderText string, ok bool) {
+	attribute := p.GetString("attribute", logic, "")
+	target := p.GetString("target", logic, "")
+	text := p.GetString("text", logic, "")
+	if unescaped, err := jsonparser.ParseString([]byte(text)); err == nil {
+		text = unescaped
+	}
+
+	var cell Cell
+	if pre {
+		cell = p.PreCell(target, text)
+		if cell.Disabled {
+			return "", false
+		}
+	} else {
+		cell = p.GetCell(target, text, false)
+	}
+
+	renderText = text
+	switch attribute {
+	case "title":
+		renderText = cell.Title
+		break
+	case "value":
+		renderText = cast.ToString(cell.Value)
+		break
+	}
+
+	if markdown {
+		renderText = MarkdownToHTML(strings.Replace(renderText, "<br>", "\n", -1))
+	}
+	return renderText, true
+}
+
+// WriteHTML writes htmlStr into a box of width starting at x and the current y, wrapping lines within the box.
+// Upon exit the current position is at the start of the line below the text.
+func (p *JSONGOFPDF) WriteHTML(pdf *gofpdf.Fpdf, x float64, width float64, lineHeight float64, htmlStr string) {
+	p.writeHTML(pdf, x, width, lineHeight, htmlStr, true)
+}
+
+// MeasureHTML returns the height WriteHTML would take to write htmlStr from the current position, with a dry run that
+// draws nothing and leaves the position unchanged.
+func (p *JSONGOFPDF) MeasureHTML(pdf *gofpdf.Fpdf, x float64, width float64, lineHeight float64, htmlStr string) float64 {
+	startX, startY := pdf.GetXY()
+	p.writeHTML(pdf, x, width, lineHeight, htmlStr, false)
+	height := pdf.GetY() - startY
+	pdf.SetXY(startX, startY)
+	return height
+}
+
+func (p *JSONGOFPDF) writeHTML(pdf *gofpdf.Fpdf, x float64, width float64, lineHeight float64, htmlStr string, draw bool) {
+	pageWidth, _ := pdf.GetPageSize()
+	leftMargin, topMargin, rightMargin, _ := pdf.GetMargins()
+	if width <= 0 {
+		width = pageWidth - rightMargin - x
+	}
+
+	baseSize, _ := pdf.GetFontSize()
+	baseR, baseG, baseB := pdf.GetTextColor()
+	baseStyle := strings.ToUpper(p.FontStyle)
+	indent := lineHeight * 1.5
+
+	margin := x
+	lineHt := lineHeight
+	bold, italic, underline := 0, 0, 0
+	gap := false
+	href := ""
+	colors := make([][3]int, 0)
+	lists := make([]int, 0) // -1 for unordered, otherwise the last item number
+
+	pdf.SetLeftMargin(x)
+	pdf.SetRightMargin(pageWidth - x - width)
+	pdf.SetX(x)
+
+	setStyle := func(size float64) {
+		style := ""
+		if bold > 0 || strings.Contains(baseStyle, "B") {
+			style += "B"
+		}
+		if italic > 0 || strings.Contains(baseStyle, "I") {
+			style += "I"
+		}
+		if underline > 0 || strings.Contains(baseStyle, "U") {
+			style += "U"
+		}
+		pdf.SetFont("", style, size)
+	}
+	setColor := func() {
+		if len(colors) > 0 {
+			color := colors[len(colors)-1]
+			pdf.SetTextColor(color[0], color[1], color[2])
+		} else {
+			pdf.SetTextColor(baseR, baseG, baseB)
+		}
+	}
+	newLine := func() {
+		if pdf.GetX() > margin+0.01 {
+			pdf.Ln(lineHt)
+		}
+		pdf.SetX(margin)
+	}
+	openGap := func() {
+		if gap {
+			pdf.Ln(lineHeight / 2)
+			pdf.SetX(margin)
+			gap = false
+		}
+	}
+
+	for _, el := range htmlTokenize(htmlStr) {
+		switch el.Cat {
+		case 'T':
+			txt := htmlSpaceRe.ReplaceAllString(html.UnescapeString(el.Str), " ")
+			if pdf.GetX() <= margin+0.01 {
+				txt = strings.TrimLeft(txt, " ")
+			}
+			if txt == "" {
+				break
+			}
+			openGap()
+			if !draw {
+				measureWrite(pdf, lineHt, p.tr(txt))
+			} else if href != "" {
+				underline++
+				setStyle(0)
+				pdf.SetTextColor(0, 0, 128)
+				pdf.WriteLinkString(lineHt, p.tr(txt), href)
+				underline--
+				setStyle(0)
+				setColor()
+			} else {
+				pdf.Write(lineHt, p.tr(txt))
+			}
+		case 'O':
+			switch el.Str {
+			case "b", "strong":
+				bold++
+				setStyle(0)
+			case "i", "em":
+				italic++
+				setStyle(0)
+			case "u":
+				underline++
+				setStyle(0)
+			case "a":
+				href = el.Attr["href"]
+			case "br":
+				pdf.Ln(lineHt)
+				pdf.SetX(margin)
+			case "p":
+				newLine()
+			case "h1", "h2", "h3":
+				newLine()
+				openGap()
+				lineHt = lineHeight * htmlHeadingSize[el.Str]
+				bold++
+				setStyle(baseSize * htmlHeadingSize[el.Str])
+			case "ul", "ol":
+				newLine()
+				openGap()
+				if el.Str == "ol" {
+					lists = append(lists, cast.ToInt(el.Attr["start"])-1)
+					if lists[len(lists)-1] < 0 {
+						lists[len(lists)-1] = 0
+					}
+				} else {
+					lists = append(lists, -1)
+				}
+				margin += indent
+				pdf.SetLeftMargin(margin)
+			case "li":
+				newLine()
+				marker := "•"
+				if len(lists) > 0 && lists[len(lists)-1] >= 0 {
+					lists[len(lists)-1]++
+					marker = strconv.Itoa(lists[len(lists)-1]) + "."
+				}
+				if draw {
+					pdf.SetX(margin - indent)
+					pdf.CellFormat(indent, lineHt, p.tr(marker), "", 0, "R", false, 0, "")
+				}
+				pdf.SetX(margin)
+			case "span", "font":
+				color := el.Attr["color"]
+				for _, rule := range strings.Split(el.Attr["style"], ";") {
+					if parts := strings.SplitN(rule, ":", 2); len(parts) == 2 && strings.TrimSpace(strings.ToLower(parts[0])) == "color" {
+						color = parts[1]
+					}
+				}
+				r, g, b, ok := ParseColor(color)
+				if !ok {
+					r, g, b = pdf.GetTextColor()
+				}
+				colors = append(colors, [3]int{r, g, b})
+				setColor()
+			}
+		case 'C':
+			switch el.Str {
+			case "b", "strong":
+				bold--
+				setStyle(0)
+			case "i", "em":
+				italic--
+				setStyle(0)
+			case "u":
+				underline--
+				setStyle(0)
+			case "a":
+				href = ""
+			case "p":
+				newLine()
+				gap = true
+			case "h1", "h2", "h3":
+				newLine()
+				lineHt = lineHeight
+				bold--
+				setStyle(baseSize)
+				gap = true
+			case "ul", "ol":
+				newLine()
+				if len(lists) > 0 {
+					lists = lists[:len(lists)-1]
+					margin -= indent
+					pdf.SetLeftMargin(margin)
+					pdf.SetX(margin)
+				}
+				if len(lists) == 0 {
+					gap = true
+				}
+			case "span", "font":
+				if len(colors) > 0 {
+					colors = colors[:len(colors)-1]
+				}
+				setColor()
+			}
+		}
+	}
+
+	if pdf.GetX() > margin+0.01 {
+		pdf.Ln(lineHt)
+	}
+
+	bold, italic, underline = 0, 0, 0
+	setStyle(baseSize)
+	pdf.SetTextColor(baseR, baseG, baseB)
+	pdf.SetMargins(leftMargin, topMargin, rightMargin)
+	pdf.SetX(leftMargin)
+}
+
+// measureWrite moves the position as pdf.Write would when writing txt, wrapping at spaces within the margins, without
+// drawing it or breaking the page.
+func measureWrite(pdf *gofpdf.Fpdf, lineHeight float64, txt string) {
+	pageWidth, _ := pdf.GetPageSize()
+	leftMargin, _, rightMargin, _ := pdf.GetMargins()
+	cellMargin := pdf.GetCellMargin()
+	chars := strings.Split(txt, "")
+	x, y := pdf.GetXY()
+
+	start, sep := 0, -1
+	for index := 0; index < len(chars); {
+		if chars[index] == "\n" {
+			index++
+			start, sep = index, -1
+			x, y = leftMargin, y+lineHeight
+			continue
+		}
+		if chars[index] == " " {
+			sep = index
+		}
+		if pdf.GetStringWidth(strings.Join(chars[start:index+1], "")) <= pageWidth-rightMargin-x-2*cellMargin {
+			index++
+			continue
+		}
+		if sep == -1 && x > leftMargin {
+			x, y = leftMargin, y+lineHeight
+			continue
+		}
+		if sep != -1 {
+			index = sep + 1
+		} else if index == start {
+			index++
+		}
+		start, sep = index, -1
+		x, y = leftMargin, y+lineHeight
+	}
+	pdf.SetXY(x+pdf.GetStringWidth(strings.Join(chars[start:], "")), y)
+}
+
+// htmlTokenize splits htmlStr into text, open tag and close tag segments. Unlike gofpdf.HTMLBasicTokenize quoted
+// attribute values may contain spaces, which span style attributes rely on.
+func htmlTokenize(htmlStr string) (list []gofpdf.HTMLBasicSegmentType) {
+	list = make([]gofpdf.HTMLBasicSegmentType, 0, 16)
+	pos := 0
+	for _, tag := range htmlTagRe.FindAllStringIndex(htmlStr, -1) {
+		if pos < tag[0] {
+			list = append(list, gofpdf.HTMLBasicSegmentType{Cat: 'T', Str: htmlStr[pos:tag[0]]})
+		}
+		pos = tag[1]
+
+		inner := strings.TrimSpace(htmlStr[tag[0]+1 : tag[1]-1])
+		if inner == "" || strings.HasPrefix(inner, "!") {
+			continue
+		}
+		if strings.HasPrefix(inner, "/") {
+			list = append(list, gofpdf.HTMLBasicSegmentType{Cat: 'C', Str: strings.ToLower(strings.TrimSpace(inner[1:]))})
+			continue
+		}
+
+		inner = strings.TrimSuffix(inner, "/")
+		name := strings.ToLower(strings.Fields(inner)[0])
+		attr := make(map[string]string)
+		for _, match := range htmlAttrRe.FindAllStringSubmatch(inner[len(name):], -1) {
+			attr[strings.ToLower(match[1])] = html.UnescapeString(match[2] + match[3] + match[4])
+		}
+		list = append(list, gofpdf.HTMLBasicSegmentType{Cat: 'O', Str: name, Attr: attr})
+	}
+	if pos < len(htmlStr) {
+		list = append(list, gofpdf.HTMLBasicSegmentType{Cat: 'T', Str: htmlStr[pos:]})
+	}
+	return list
+}
+
+// MarkdownToHTML converts headings (# to ###), bullet and numbered lists, links, bold and italic markdown into the
+// HTML subset understood by WriteHTML. Blank lines separate paragraphs and single line breaks are kept.
+func MarkdownToHTML(markdown string) string {
+	var out strings.Builder
+	block := ""
+	closeBlock := func() {
+		switch block {
+		case "p":
+			out.WriteString("</p>")
+		case "ul":
+			out.WriteString("</ul>")
+		case "ol":
+			out.WriteString("</ol>")
+		}
+		block = ""
+	}
+	openBlock := func(name string) {
+		if block != name {
+			closeBlock()
+			out.WriteString("<" + name + ">")
+			block = name
+		}
+	}
+
+	lines := strings.Split(strings.Replace(markdown, "\r", "", -1), "\n")
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			closeBlock()
+			continue
+		}
+		if match := markdownHeadRe.FindStringSubmatch(line); match != nil {
+			closeBlock()
+			level := strconv.Itoa(len(match[1]))
+			out.WriteString("<h" + level + ">" + markdownInline(match[2]) + "</h" + level + ">")
+			continue
+		}
+		if match := markdownULRe.FindStringSubmatch(line); match != nil {
+			openBlock("ul")
+			out.WriteString("<li>" + markdownInline(match[1]) + "</li>")
+			continue
+		}
+		if match := markdownOLRe.FindStringSubmatch(line); match != nil {
+			openBlock("ol")
+			out.WriteString("<li>" + markdownInline(match[1]) + "</li>")
+			continue
+		}
+		if block == "p" {
+			out.WriteString("<br>")
+		}
+		openBlock("p")
+		out.WriteString(markdownInline(strings.TrimSpace(line)))
+	}
+	closeBlock()
+
+	return out.String()
+}
+
+func markdownInline(text string) string {
+	text = html.EscapeString(text)
+	text = markdownBoldRe.ReplaceAllString(text, "$2<b>$1$3</b>")
+	text = markdownItalRe.ReplaceAllString(text, "$2<i>$1$3</i>")
+	text = markdownLinkRe.ReplaceAllString(text, `<a href="$2">$1</a>`)
+	return text
+}
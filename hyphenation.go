@@ -0,0 +1,204 @@
+package jsongofpdf
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Hyphenator finds hyphenation points in words using Liang's algorithm over TeX hyphenation patterns.
+type Hyphenator struct {
+	patterns   map[string][]int
+	exceptions map[string][]int
+	maxLength  int
+	LeftMin    int
+	RightMin   int
+}
+
+var (
+	hyphenators      = make(map[string]*Hyphenator)
+	hyphenatorsMutex sync.Mutex
+	hyphenVowels     = "aeiouyäöüàáâèéêëìíîïòóôùúûæœ"
+)
+
+// NewHyphenator parses whitespace separated TeX patterns such as "a1b" or ".ab4c" and exceptions such as "ta-ble".
+// leftMin and rightMin are the fewest characters left before and after a hyphen.
+func NewHyphenator(patterns string, exceptions string, leftMin int, rightMin int) *Hyphenator {
+	h := &Hyphenator{
+		patterns:   make(map[string][]int),
+		exceptions: make(map[string][]int),
+		LeftMin:    leftMin,
+		RightMin:   rightMin,
+	}
+
+	for _, pattern := range strings.Fields(patterns) {
+		letters := make([]rune, 0, len(pattern))
+		values := []int{0}
+		for _, char := range pattern {
+			if char >= '0' && char <= '9' {
+				values[len(values)-1] = int(char - '0')
+				continue
+			}
+			letters = append(letters, unicode.ToLower(char))
+			values = append(values, 0)
+		}
+		h.patterns[string(letters)] = values
+		if len(letters) > h.maxLength {
+			h.maxLength = len(letters)
+		}
+	}
+
+	for _, exception := range strings.Fields(exceptions) {
+		breaks := make([]int, 0)
+		length := 0
+		for _, char := range exception {
+			if char == '-' {
+				breaks = append(breaks, length)
+				continue
+			}
+			length++
+		}
+		h.exceptions[strings.ToLower(strings.Replace(exception, "-", "", -1))] = breaks
+	}
+
+	return h
+}
+
+// Breaks returns the positions in word, counted in characters, where a hyphen may be inserted. Positions that would
+// leave a part without a vowel are skipped.
+func (h *Hyphenator) Breaks(word []rune) []int {
+	lower := make([]rune, len(word))
+	for i, char := range word {
+		lower[i] = unicode.ToLower(char)
+	}
+
+	breaks, ok := h.exceptions[string(lower)]
+	if !ok {
+		breaks = make([]int, 0)
+		dotted := append(append([]rune{'.'}, lower...), '.')
+		values := make([]int, len(dotted)+1)
+		for i := range dotted {
+			for j := i + 1; j <= len(dotted) && j-i <= h.maxLength; j++ {
+				if pattern, ok := h.patterns[string(dotted[i:j])]; ok {
+					for k, value := range pattern {
+						if value > values[i+k] {
+							values[i+k] = value
+						}
+					}
+				}
+			}
+		}
+		for position := 1; position < len(lower); position++ {
+			if values[position+1]%2 == 1 {
+				breaks = append(breaks, position)
+			}
+		}
+	}
+
+	valid := make([]int, 0, len(breaks))
+	for _, position := range breaks {
+		if position < h.LeftMin || len(lower)-position < h.RightMin {
+			continue
+		}
+		if !strings.ContainsAny(string(lower[:position]), hyphenVowels) || !strings.ContainsAny(string(lower[position:]), hyphenVowels) {
+			continue
+		}
+		valid = append(valid, position)
+	}
+	return valid
+}
+
+// Hyphenate returns word with hyphens inserted at every hyphenation point.
+func (h *Hyphenator) Hyphenate(word string) string {
+	runes := []rune(word)
+	result := make([]rune, 0, len(runes)+4)
+	last := 0
+	for _, position := range h.Breaks(runes) {
+		result = append(append(result, runes[last:position]...), '-')
+		last = position
+	}
+	return string(append(result, runes[last:]...))
+}
+
+// RegisterHyphenation adds or replaces the patterns used for language, for example to supply a TeX pattern file for a
+// language not embedded in the package or the traditional spelling of hyph-de-1901.pat.txt for "de".
+func RegisterHyphenation(language string, patterns string, exceptions string, leftMin int, rightMin int) {
+	hyphenatorsMutex.Lock()
+	defer hyphenatorsMutex.Unlock()
+	hyphenators[strings.ToLower(language)] = NewHyphenator(patterns, exceptions, leftMin, rightMin)
+}
+
+// GetHyphenator returns the hyphenator for a language such as "en", "en-gb" or "de", building the embedded patterns on
+// first use. nil is returned for unknown languages.
+func GetHyphenator(language string) *Hyphenator {
+	hyphenatorsMutex.Lock()
+	defer hyphenatorsMutex.Unlock()
+
+	language = strings.ToLower(language)
+	if h, ok := hyphenators[language]; ok {
+		return h
+	}
+	base := strings.SplitN(strings.Replace(language, "_", "-", -1), "-", 2)[0]
+	if h, ok := hyphenators[base]; ok {
+		return h
+	}
+
+	switch base {
+	case "en":
+		hyphenators[base] = NewHyphenator(hyphenationPatternsEN, hyphenationExceptionsEN, 2, 3)
+		break
+	case "de":
+		hyphenators[base] = NewHyphenator(hyphenationPatternsDE, "", 2, 2)
+		break
+	default:
+		return nil
+	}
+	return hyphenators[base]
+}
+
+// hyphenateWord splits word so the head with a trailing hyphen fits on line within maxWidth. Existing hyphens in
+// compound words are used as break points as well. An empty head is returned when no break fits.
+func (p *JSONGOFPDF) hyphenateWord(pdf *gofpdf.Fpdf, line string, word string, maxWidth float64, style *TextStyle) (head string, tail string) {
+	h := GetHyphenator(style.Hyphenate)
+	if h == nil {
+		return "", word
+	}
+
+	// Text is cp1252 encoded by the time it is split, so each byte is one character
+	start, end := 0, len(word)
+	for start < end && !unicode.IsLetter(rune(word[start])) {
+		start++
+	}
+	for end > start && !unicode.IsLetter(rune(word[end-1])) {
+		end--
+	}
+	runes := make([]rune, end-start)
+	for i := range runes {
+		runes[i] = rune(word[start+i])
+	}
+
+	candidates := make(map[int]string)
+	for _, position := range h.Breaks(runes) {
+		candidates[start+position] = word[:start+position] + "-"
+	}
+	for i := 1; i < len(word)-1; i++ {
+		if word[i] == '-' {
+			candidates[i+1] = word[:i+1]
+		}
+	}
+
+	for position := len(word) - 1; position > 0; position-- {
+		if candidate, ok := candidates[position]; ok {
+			joined := candidate
+			if line != "" {
+				joined = line + " " + candidate
+			}
+			if p.TextWidth(pdf, joined, style) <= maxWidth {
+				return candidate, word[position:]
+			}
+		}
+	}
+	return "", word
+}
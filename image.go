@@ -0,0 +1,225 @@
+package jsongofpdf
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+var (
+	ErrImageNotFound   = errors.New("Image not found")
+	ErrImageType       = errors.New("Unsupported image type")
+	ErrImageDataURI    = errors.New("Invalid image data uri")
+	ErrImageDataFormat = errors.New("Image data must be a data uri, 0x prefixed hex or base64")
+)
+
+// GetImageData returns the bytes of the image src refers to. src may be a base64 data uri, a name in the Images passed to
+// New, a name resolved by the ImageProvider or a file path, tried in that order.
+func (p *JSONGOFPDF) GetImageData(src string) (data []byte, err error) {
+	if strings.HasPrefix(src, "data:") {
+		return DecodeImageData(src)
+	}
+
+	if data, ok := p.Images[src]; ok {
+		return data, nil
+	}
+
+	if p.ImageProvider != nil {
+		data, err = p.ImageProvider.GetImage(src)
+		if err == nil {
+			return data, nil
+		}
+		if err != ErrImageNotFound {
+			return nil, err
+		}
+	}
+
+	data, err = ioutil.ReadFile(src)
+	if err != nil {
+		return nil, ErrImageNotFound
+	}
+	return data, nil
+}
+
+// GetDataImage returns the bytes of the image stored in the bound Data at path, for example "company.logo" or "items.[0].photo".
+func (p *JSONGOFPDF) GetDataImage(path string) (data []byte, err error) {
+	value, err := p.GetDataString(path)
+	if err != nil {
+		return nil, ErrImageNotFound
+	}
+	return DecodeImageData(value)
+}
+
+// DecodeImageData decodes an image stored as text: a base64 data uri, hex prefixed with "0x" as stored by MSSQL or plain base64.
+func DecodeImageData(value string) (data []byte, err error) {
+	value = strings.TrimSpace(value)
+
+	if strings.HasPrefix(value, "data:") {
+		comma := strings.Index(value, ",")
+		if comma < 0 || !strings.HasSuffix(value[:comma], ";base64") {
+			return nil, ErrImageDataURI
+		}
+		return base64.StdEncoding.DecodeString(value[comma+1:])
+	}
+
+	if strings.HasPrefix(value, "0x") {
+		return hex.DecodeString(value[2:])
+	}
+
+	data, err = base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrImageDataFormat
+	}
+	return data, nil
+}
+
+// RegisterImage registers data with gofpdf under name unless an image of that name is already registered, so an image
+// used on every page is only embedded once. The image type is detected from the data and formats gofpdf cannot embed are
+// converted by ConvertImage. The natural size of the image follows its embedded resolution (JFIF density, PNG pHYs or BMP
+// resolution) or the DPI of the template when it has none. width and height are the placed size used by CompressImage,
+// 0 when not known, see placedImageName for naming images downsampled to it. Data seen before under another name reuses
+// the processed image when placed at the same size.
+func (p *JSONGOFPDF) RegisterImage(pdf *gofpdf.Fpdf, name string, data []byte, width float64, height float64) (info *gofpdf.ImageInfoType, err error) {
+	if info = pdf.GetImageInfo(name); info != nil {
+		return info, nil
+	}
+
+	key := imageKey{hash: sha1.Sum(data)}
+	if p.ImageMaxDPI > 0 {
+		key.width, key.height = width, height
+	}
+	prepared, ok := p.imageCache[key]
+	if !ok {
+		prepared, err = p.prepareImage(pdf, data, width, height)
+		if err != nil {
+			return nil, err
+		}
+		if p.imageCache == nil {
+			p.imageCache = make(map[imageKey]preparedImage)
+		}
+		p.imageCache[key] = prepared
+	}
+
+	options := gofpdf.ImageOptions{
+		ReadDpi:   false,
+		ImageType: prepared.imageType,
+	}
+	info = pdf.RegisterImageOptionsReader(name, options, bytes.NewReader(prepared.data))
+	if pdf.Err() {
+		return nil, pdf.Error()
+	}
+	if prepared.dpi > 0 {
+		info.SetDpi(prepared.dpi)
+	}
+	return info, nil
+}
+
+// placedImageName returns the name an image placed at width by height is registered under. Images are downsampled to their
+// placed size when ImageMaxDPI is set, so they are registered once for each size and a larger placement of the same image
+// does not reuse a smaller copy. gofpdf embeds copies that come out the same only once.
+func (p *JSONGOFPDF) placedImageName(name string, width float64, height float64) string {
+	if p.ImageMaxDPI <= 0 || (width <= 0 && height <= 0) {
+		return name
+	}
+	return fmt.Sprintf("%s@%gx%g", name, width, height)
+}
+
+// FitImage draws the registered image name into the box at x, y of width and height. fit "contain" scales the image to
+// fit inside the box, "cover" scales it to fill the box clipping the overflow, "fill" stretches it to the box and "none"
+// keeps the natural size clipped to the box. align "L", "C" or "R" and valign "T", "M" or "B" position the image in the box.
+// A box with no width or height is sized from the image aspect ratio. The size of the box is returned.
+func (p *JSONGOFPDF) FitImage(pdf *gofpdf.Fpdf, name string, x float64, y float64, width float64, height float64, fit string, align string, valign string, link int, linkStr string) (boxWidth float64, boxHeight float64) {
+	info := pdf.GetImageInfo(name)
+	if info == nil {
+		return 0, 0
+	}
+	box := fitBox(info.Width(), info.Height(), x, y, width, height, fit, align, valign)
+	if box.width <= 0 || box.height <= 0 {
+		return 0, 0
+	}
+
+	clip := box.drawWidth > box.width+0.001 || box.drawHeight > box.height+0.001
+	if clip {
+		pdf.ClipRect(x, y, box.width, box.height, false)
+	}
+	pdf.ImageOptions(name, box.drawX, box.drawY, box.drawWidth, box.drawHeight, false, gofpdf.ImageOptions{}, 0, "")
+	if clip {
+		pdf.ClipEnd()
+	}
+	if link > 0 {
+		pdf.Link(x, y, box.width, box.height, link)
+	} else if linkStr != "" {
+		pdf.LinkString(x, y, box.width, box.height, linkStr)
+	}
+
+	return box.width, box.height
+}
+
+// fittedBox is the box an image is fitted into and the position and size the image is drawn at.
+type fittedBox struct {
+	width      float64
+	height     float64
+	drawX      float64
+	drawY      float64
+	drawWidth  float64
+	drawHeight float64
+}
+
+// fitBox scales content of naturalWidth by naturalHeight into the box at x, y by fit, align and valign as described by FitImage.
+func fitBox(naturalWidth float64, naturalHeight float64, x float64, y float64, width float64, height float64, fit string, align string, valign string) (box fittedBox) {
+	if naturalWidth <= 0 || naturalHeight <= 0 {
+		return box
+	}
+
+	if width == 0 && height == 0 {
+		width, height = naturalWidth, naturalHeight
+	} else if width == 0 {
+		width = height * naturalWidth / naturalHeight
+	} else if height == 0 {
+		height = width * naturalHeight / naturalWidth
+	}
+	box.width, box.height = width, height
+
+	box.drawWidth, box.drawHeight = width, height
+	switch fit {
+	case "contain":
+		scale := math.Min(width/naturalWidth, height/naturalHeight)
+		box.drawWidth, box.drawHeight = naturalWidth*scale, naturalHeight*scale
+		break
+	case "cover":
+		scale := math.Max(width/naturalWidth, height/naturalHeight)
+		box.drawWidth, box.drawHeight = naturalWidth*scale, naturalHeight*scale
+		break
+	case "none":
+		box.drawWidth, box.drawHeight = naturalWidth, naturalHeight
+		break
+	}
+
+	box.drawX, box.drawY = x, y
+	switch strings.ToUpper(align) {
+	case "C", "CENTER":
+		box.drawX += (width - box.drawWidth) / 2
+		break
+	case "R", "RIGHT":
+		box.drawX += width - box.drawWidth
+		break
+	}
+	switch strings.ToUpper(valign) {
+	case "M", "MIDDLE":
+		box.drawY += (height - box.drawHeight) / 2
+		break
+	case "B", "BOTTOM":
+		box.drawY += height - box.drawHeight
+		break
+	}
+
+	return box
+}
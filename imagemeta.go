@@ -0,0 +1,320 @@
+package jsongofpdf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+)
+
+var (
+	ErrImageTruncated = errors.New("Image data is truncated")
+	ErrImageHeader    = errors.New("Image header is invalid")
+)
+
+// ImageMeta is the metadata read from the header of an image. DPIX and DPIY are 0 when the image does not record its
+// resolution. Components is the number of JPEG colour components, 4 for CMYK.
+type ImageMeta struct {
+	Type        string
+	Width       int
+	Height      int
+	DPIX        float64
+	DPIY        float64
+	Components  int
+	Progressive bool
+	Interlaced  bool
+}
+
+// readAt reads exactly len(buf) bytes at offset, reporting ErrImageTruncated when the data ends early.
+func readAt(r io.ReaderAt, buf []byte, offset int64) error {
+	n, err := r.ReadAt(buf, offset)
+	if n == len(buf) {
+		return nil
+	}
+	if err == nil || err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrImageTruncated
+	}
+	return err
+}
+
+// GetImageMeta detects the type of the image in r and reads its metadata. JPEG, PNG, GIF and BMP images are supported.
+func GetImageMeta(r io.ReaderAt) (meta ImageMeta, err error) {
+	head := make([]byte, 8)
+	n, _ := r.ReadAt(head, 0)
+	if n < 2 {
+		return meta, ErrImageTruncated
+	}
+	head = head[:n]
+
+	switch {
+	case head[0] == 0xFF && head[1] == 0xD8:
+		return GetJpgMeta(r)
+	case bytes.Equal(head, []byte("\x89PNG\r\n\x1a\n")):
+		return GetPngMeta(r)
+	case bytes.HasPrefix(head, []byte("GIF8")):
+		return GetGifMeta(r)
+	case bytes.HasPrefix(head, []byte("BM")):
+		return GetBmpMeta(r)
+	}
+	return meta, ErrImageType
+}
+
+// GetImageMetaBytes reads the metadata of the image held in data.
+func GetImageMetaBytes(data []byte) (meta ImageMeta, err error) {
+	return GetImageMeta(bytes.NewReader(data))
+}
+
+// GetJpgMeta reads the size from any JPEG start of frame marker, baseline, extended, progressive or lossless, along with
+// the number of colour components and the JFIF pixel density.
+func GetJpgMeta(r io.ReaderAt) (meta ImageMeta, err error) {
+	meta.Type = "jpg"
+	buf := make([]byte, 16)
+	if err = readAt(r, buf[:2], 0); err != nil {
+		return meta, err
+	}
+	if buf[0] != 0xFF || buf[1] != 0xD8 {
+		return meta, ErrImageHeader
+	}
+
+	offset := int64(2)
+	for {
+		if err = readAt(r, buf[:2], offset); err != nil {
+			return meta, err
+		}
+		if buf[0] != 0xFF {
+			return meta, ErrImageHeader
+		}
+		marker := buf[1]
+		// Markers may be preceded by any number of fill bytes
+		if marker == 0xFF {
+			offset++
+			continue
+		}
+		// Standalone markers carry no length
+		if marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7) {
+			offset += 2
+			continue
+		}
+		// Image data or the end of the image before a frame header
+		if marker == 0xDA || marker == 0xD9 {
+			return meta, ErrImageHeader
+		}
+
+		if err = readAt(r, buf[:2], offset+2); err != nil {
+			return meta, err
+		}
+		length := int64(binary.BigEndian.Uint16(buf[:2]))
+		if length < 2 {
+			return meta, ErrImageHeader
+		}
+
+		switch marker {
+		case 0xE0:
+			if length >= 16 {
+				if err = readAt(r, buf[:12], offset+4); err != nil {
+					return meta, err
+				}
+				if string(buf[:5]) == "JFIF\x00" {
+					densityX := float64(binary.BigEndian.Uint16(buf[8:10]))
+					densityY := float64(binary.BigEndian.Uint16(buf[10:12]))
+					switch buf[7] {
+					case 1:
+						meta.DPIX, meta.DPIY = densityX, densityY
+						break
+					case 2:
+						meta.DPIX, meta.DPIY = densityX*2.54, densityY*2.54
+						break
+					}
+				}
+			}
+			break
+		case 0xC0, 0xC1, 0xC2, 0xC3, 0xC5, 0xC6, 0xC7, 0xC9, 0xCA, 0xCB, 0xCD, 0xCE, 0xCF:
+			if err = readAt(r, buf[:6], offset+4); err != nil {
+				return meta, err
+			}
+			meta.Height = int(binary.BigEndian.Uint16(buf[1:3]))
+			meta.Width = int(binary.BigEndian.Uint16(buf[3:5]))
+			meta.Components = int(buf[5])
+			meta.Progressive = marker == 0xC2 || marker == 0xC6 || marker == 0xCA || marker == 0xCE
+			if meta.Width == 0 || meta.Height == 0 {
+				return meta, ErrImageHeader
+			}
+			return meta, nil
+		}
+
+		offset += 2 + length
+	}
+}
+
+// GetPngMeta reads the size and interlace method from the IHDR chunk and the resolution from a pHYs chunk in metres.
+func GetPngMeta(r io.ReaderAt) (meta ImageMeta, err error) {
+	meta.Type = "png"
+	buf := make([]byte, 33)
+	if err = readAt(r, buf, 0); err != nil {
+		return meta, err
+	}
+	if string(buf[:8]) != "\x89PNG\r\n\x1a\n" || string(buf[12:16]) != "IHDR" {
+		return meta, ErrImageHeader
+	}
+	meta.Width = int(binary.BigEndian.Uint32(buf[16:20]))
+	meta.Height = int(binary.BigEndian.Uint32(buf[20:24]))
+	meta.Interlaced = buf[28] == 1
+	if meta.Width == 0 || meta.Height == 0 {
+		return meta, ErrImageHeader
+	}
+
+	// pHYs must come before the image data
+	offset := int64(33)
+	for {
+		if readAt(r, buf[:8], offset) != nil {
+			return meta, nil
+		}
+		length := int64(binary.BigEndian.Uint32(buf[:4]))
+		chunk := string(buf[4:8])
+		if chunk == "IDAT" || chunk == "IEND" {
+			return meta, nil
+		}
+		if chunk == "pHYs" && length == 9 {
+			if err = readAt(r, buf[:9], offset+8); err != nil {
+				return meta, err
+			}
+			if buf[8] == 1 {
+				meta.DPIX = float64(binary.BigEndian.Uint32(buf[0:4])) * 0.0254
+				meta.DPIY = float64(binary.BigEndian.Uint32(buf[4:8])) * 0.0254
+			}
+			return meta, nil
+		}
+		offset += 12 + length
+	}
+}
+
+// GetGifMeta reads the logical screen size and whether the first image is interlaced.
+func GetGifMeta(r io.ReaderAt) (meta ImageMeta, err error) {
+	meta.Type = "gif"
+	buf := make([]byte, 13)
+	if err = readAt(r, buf, 0); err != nil {
+		return meta, err
+	}
+	if string(buf[:6]) != "GIF87a" && string(buf[:6]) != "GIF89a" {
+		return meta, ErrImageHeader
+	}
+	meta.Width = int(binary.LittleEndian.Uint16(buf[6:8]))
+	meta.Height = int(binary.LittleEndian.Uint16(buf[8:10]))
+
+	offset := int64(13)
+	if buf[10]&0x80 != 0 {
+		offset += 3 << (uint(buf[10]&0x07) + 1)
+	}
+	for {
+		if err = readAt(r, buf[:1], offset); err != nil {
+			return meta, err
+		}
+		switch buf[0] {
+		case 0x21:
+			// Extensions are a label followed by data sub-blocks ending with an empty block
+			offset += 2
+			for {
+				if err = readAt(r, buf[:1], offset); err != nil {
+					return meta, err
+				}
+				offset += 1 + int64(buf[0])
+				if buf[0] == 0 {
+					break
+				}
+			}
+			break
+		case 0x2C:
+			if err = readAt(r, buf[:10], offset); err != nil {
+				return meta, err
+			}
+			meta.Interlaced = buf[9]&0x40 != 0
+			return meta, nil
+		case 0x3B:
+			return meta, nil
+		default:
+			return meta, ErrImageHeader
+		}
+	}
+}
+
+// GetBmpMeta reads the size and resolution from the bitmap info header. Top down bitmaps store a negative height.
+func GetBmpMeta(r io.ReaderAt) (meta ImageMeta, err error) {
+	meta.Type = "bmp"
+	buf := make([]byte, 46)
+	if err = readAt(r, buf[:26], 0); err != nil {
+		return meta, err
+	}
+	if string(buf[:2]) != "BM" {
+		return meta, ErrImageHeader
+	}
+
+	headerSize := binary.LittleEndian.Uint32(buf[14:18])
+	if headerSize == 12 {
+		meta.Width = int(binary.LittleEndian.Uint16(buf[18:20]))
+		meta.Height = int(binary.LittleEndian.Uint16(buf[20:22]))
+		return meta, nil
+	}
+
+	meta.Width = int(int32(binary.LittleEndian.Uint32(buf[18:22])))
+	meta.Height = int(int32(binary.LittleEndian.Uint32(buf[22:26])))
+	if meta.Height < 0 {
+		meta.Height = -meta.Height
+	}
+	if headerSize >= 40 {
+		if err = readAt(r, buf, 0); err != nil {
+			return meta, err
+		}
+		meta.DPIX = float64(int32(binary.LittleEndian.Uint32(buf[38:42]))) * 0.0254
+		meta.DPIY = float64(int32(binary.LittleEndian.Uint32(buf[42:46]))) * 0.0254
+	}
+	return meta, nil
+}
+
+// GetJpgSize returns the pixel size of a JPEG.
+func GetJpgSize(r io.ReaderAt) (width int, height int, err error) {
+	meta, err := GetJpgMeta(r)
+	return meta.Width, meta.Height, err
+}
+
+// GetGifSize returns the pixel size of a GIF.
+func GetGifSize(r io.ReaderAt) (width int, height int, err error) {
+	meta, err := GetGifMeta(r)
+	return meta.Width, meta.Height, err
+}
+
+// GetBmpSize returns the pixel size of a BMP.
+func GetBmpSize(r io.ReaderAt) (width int, height int, err error) {
+	meta, err := GetBmpMeta(r)
+	return meta.Width, meta.Height, err
+}
+
+// GetPngSize returns the pixel size of a PNG.
+func GetPngSize(r io.ReaderAt) (width int, height int, err error) {
+	meta, err := GetPngMeta(r)
+	return meta.Width, meta.Height, err
+}
+
+// GetJpgDimensions returns the pixel size of a JPEG file, 0 when it cannot be read. Use GetJpgSize for the error.
+func GetJpgDimensions(file *os.File) (width int, height int) {
+	width, height, _ = GetJpgSize(file)
+	return width, height
+}
+
+// GetGifDimensions returns the pixel size of a GIF file, 0 when it cannot be read. Use GetGifSize for the error.
+func GetGifDimensions(file *os.File) (width int, height int) {
+	width, height, _ = GetGifSize(file)
+	return width, height
+}
+
+// GetBmpDimensions returns the pixel size of a BMP file, 0 when it cannot be read. Use GetBmpSize for the error.
+func GetBmpDimensions(file *os.File) (width int, height int) {
+	width, height, _ = GetBmpSize(file)
+	return width, height
+}
+
+// GetPngDimensions returns the pixel size of a PNG file, 0 when it cannot be read. Use GetPngSize for the error.
+func GetPngDimensions(file *os.File) (width int, height int) {
+	width, height, _ = GetPngSize(file)
+	return width, height
+}
@@ -0,0 +1,113 @@
+package jsongofpdf
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math"
+	"os"
+	"testing"
+)
+
+func TestGetImageMeta(t *testing.T) {
+	tests := []struct {
+		file        string
+		kind        string
+		width       int
+		height      int
+		dpi         float64
+		components  int
+		progressive bool
+		interlaced  bool
+		err         error
+	}{
+		{file: "baseline.jpg", kind: "jpg", width: 16, height: 8, dpi: 300, components: 3},
+		{file: "extended.jpg", kind: "jpg", width: 16, height: 8, dpi: 299.72, components: 3},
+		{file: "progressive.jpg", kind: "jpg", width: 150, height: 103, components: 1, progressive: true},
+		{file: "cmyk.jpg", kind: "jpg", width: 150, height: 103, dpi: 72, components: 4},
+		{file: "truncated.jpg", kind: "jpg", err: ErrImageTruncated},
+		{file: "plain.png", kind: "png", width: 10, height: 5},
+		{file: "phys.png", kind: "png", width: 10, height: 5, dpi: 96.012},
+		{file: "interlaced.gif", kind: "gif", width: 6, height: 4, interlaced: true},
+		{file: "image.bmp", kind: "bmp", width: 7, height: 3, dpi: 72.009},
+	}
+
+	for _, test := range tests {
+		data, err := ioutil.ReadFile("testdata/" + test.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		meta, err := GetImageMeta(bytes.NewReader(data))
+		if err != test.err {
+			t.Errorf("%s: expected error %v, got %v", test.file, test.err, err)
+			continue
+		}
+		if test.err != nil {
+			continue
+		}
+		if meta.Type != test.kind || meta.Width != test.width || meta.Height != test.height {
+			t.Errorf("%s: expected %s %dx%d, got %s %dx%d", test.file, test.kind, test.width, test.height, meta.Type, meta.Width, meta.Height)
+		}
+		if math.Abs(meta.DPIX-test.dpi) > 0.01 || math.Abs(meta.DPIY-test.dpi) > 0.01 {
+			t.Errorf("%s: expected %v dpi, got %vx%v", test.file, test.dpi, meta.DPIX, meta.DPIY)
+		}
+		if test.kind == "jpg" && meta.Components != test.components {
+			t.Errorf("%s: expected %d components, got %d", test.file, test.components, meta.Components)
+		}
+		if meta.Progressive != test.progressive || meta.Interlaced != test.interlaced {
+			t.Errorf("%s: expected progressive %v interlaced %v, got %v %v", test.file, test.progressive, test.interlaced, meta.Progressive, meta.Interlaced)
+		}
+	}
+}
+
+func TestGetImageDimensionsFile(t *testing.T) {
+	file, err := os.Open("testdata/baseline.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	width, height, err := GetJpgSize(file)
+	if err != nil || width != 16 || height != 8 {
+		t.Fatalf("expected 16x8, got %dx%d %v", width, height, err)
+	}
+
+	width, height = GetJpgDimensions(file)
+	if width != 16 || height != 8 {
+		t.Fatalf("expected 16x8, got %dx%d", width, height)
+	}
+}
+
+func TestGetImageMetaInvalid(t *testing.T) {
+	tests := []struct {
+		data []byte
+		err  error
+	}{
+		{data: []byte{}, err: ErrImageTruncated},
+		{data: []byte("not an image"), err: ErrImageType},
+		{data: []byte("\xff\xd8\x00\x00"), err: ErrImageHeader},
+		{data: []byte("\xff\xd8\xff\xda\x00\x08"), err: ErrImageHeader},
+		{data: []byte("GIF89a\x01"), err: ErrImageTruncated},
+	}
+
+	for _, test := range tests {
+		if _, err := GetImageMetaBytes(test.data); err != test.err {
+			t.Errorf("%q: expected error %v, got %v", test.data, test.err, err)
+		}
+	}
+}
+
+func TestGetImageUnsupportedMeta(t *testing.T) {
+	file, err := ioutil.TempFile("", "image*.webp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	file.WriteString("RIFF\x24\x00\x00\x00WEBPVP8 ")
+	file.Close()
+
+	image, err := GetImage(file.Name())
+	if err != nil || image.Type != "webp" || image.Data == "" || image.Width != 0 || image.Height != 0 {
+		t.Fatalf("expected webp data without a size, got %q %dx%d %v", image.Type, image.Width, image.Height, err)
+	}
+}
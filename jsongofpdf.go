@@ -12,8 +12,16 @@ func New(options JSONGOFPDFOptions) (*JSONGOFPDF, error) {
 	jsongofpdf.Logic = options.Logic
 	jsongofpdf.Tables = options.Tables
 	jsongofpdf.Globals = options.Globals
+	jsongofpdf.Data = options.Data
+	jsongofpdf.Images = options.Images
+	jsongofpdf.ImageProvider = options.ImageProvider
 
-	jsongofpdf.DPI = 18
+	jsongofpdf.keepTogether = true
+
+	jsongofpdf.DPI = options.DPI
+	if jsongofpdf.DPI <= 0 {
+		jsongofpdf.DPI = 96
+	}
 
 	return jsongofpdf, nil
 }
@@ -69,6 +77,12 @@ func (p *JSONGOFPDF) RunOperation(pdf *gofpdf.Fpdf, name string, logic string) (
 	case "setfont":
 		pdf = p.SetFont(pdf, logic)
 		break
+	case "setimagecompression":
+		pdf = p.SetImageCompression(pdf, logic)
+		break
+	case "sethyphenation":
+		pdf = p.SetHyphenation(pdf, logic)
+		break
 	case "setx":
 		pdf = p.SetX(pdf, logic)
 		break
@@ -126,6 +140,15 @@ func (p *JSONGOFPDF) RunOperation(pdf *gofpdf.Fpdf, name string, logic string) (
 	case "setdrawcolor":
 		pdf = p.SetDrawColor(pdf, logic)
 		break
+	case "table":
+		pdf = p.TableOperation(pdf, logic)
+		break
+	case "tableheader":
+		pdf = p.TableHeaderRows(pdf)
+		break
+	case "tablecell":
+		pdf = p.TableCell(pdf, logic)
+		break
 	case "tablefunc":
 		pdf = p.TableFunc(pdf, logic)
 		break
@@ -135,6 +158,33 @@ func (p *JSONGOFPDF) RunOperation(pdf *gofpdf.Fpdf, name string, logic string) (
 	case "image":
 		pdf = p.Image(pdf, logic)
 		break
+	case "barcode":
+		pdf = p.Barcode(pdf, logic)
+		break
+	case "qrcode":
+		pdf = p.QRCode(pdf, logic)
+		break
+	case "datamatrix":
+		pdf = p.DataMatrix(pdf, logic)
+		break
+	case "pdf417":
+		pdf = p.PDF417(pdf, logic)
+		break
+	case "swissqrbill":
+		pdf = p.SwissQRBill(pdf, logic)
+		break
+	case "epcslip":
+		pdf = p.EPCSlip(pdf, logic)
+		break
+	case "chart":
+		pdf = p.Chart(pdf, logic)
+		break
+	case "sparkline":
+		pdf = p.Sparkline(pdf, logic)
+		break
+	case "svg":
+		pdf = p.SVG(pdf, logic)
+		break
 	case "multicell":
 		pdf = p.MultiCell(pdf, logic)
 		break
@@ -147,6 +197,12 @@ func (p *JSONGOFPDF) RunOperation(pdf *gofpdf.Fpdf, name string, logic string) (
 	case "line":
 		pdf = p.Line(pdf, logic)
 		break
+	case "html":
+		pdf = p.HTML(pdf, logic)
+		break
+	case "markdown":
+		pdf = p.Markdown(pdf, logic)
+		break
 	default:
 		return pdf
 	}
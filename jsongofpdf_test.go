@@ -40,13 +40,14 @@ import (
 // }
 
 func TestGetString(t *testing.T) {
-	logic := `
-	"new": {
+	logic := `{
 		"orientation": "P"
 	}`
 
+	p := &JSONGOFPDF{}
+
 	// Should return true
-	result := GetString("orientation", logic, "")
+	result := p.GetString("orientation", logic, "")
 
 	if result != "P" {
 		t.Fatal("Logic should return P")
@@ -54,13 +55,14 @@ func TestGetString(t *testing.T) {
 }
 
 func TestGetInt(t *testing.T) {
-	logic := `
-	"setfont": {
+	logic := `{
 		"size": 10
 	}`
 
+	p := &JSONGOFPDF{}
+
 	// Should return true
-	result := GetInt("size", logic, 8)
+	result := p.GetInt("size", logic, 8)
 
 	if result != 10 {
 		t.Fatal("Logic should return 10")
@@ -68,12 +70,43 @@ func TestGetInt(t *testing.T) {
 }
 
 func TestGetStringFallback(t *testing.T) {
-	logic := `"new": {}`
+	logic := `{}`
+
+	p := &JSONGOFPDF{}
 
 	// Should return true
-	result := GetString("orientation", logic, "P")
+	result := p.GetString("orientation", logic, "P")
 
 	if result != "P" {
 		t.Fatal("Logic should return P fallback")
 	}
 }
+
+func TestGetCellEmptyRow(t *testing.T) {
+	p := &JSONGOFPDF{Tables: []Table{{Rows: []Row{{}}}}}
+
+	// Should not panic on a row without cells
+	cell := p.GetCell("", "", false)
+
+	if cell.Value != nil {
+		t.Fatal("Row without cells should return an empty cell")
+	}
+}
+
+func TestGetBarcodeValueOutsideTable(t *testing.T) {
+	p := &JSONGOFPDF{Tables: []Table{{Rows: []Row{{Cells: []Cell{{Key: "sku", Path: "sku", Value: "4006381333931"}}}}}}}
+
+	// Should not fall back to the first cell of the table
+	if value := p.GetBarcodeValue(`{}`); value != "" {
+		t.Fatalf("Barcode without a source outside a table should be empty, got %q", value)
+	}
+
+	if value := p.GetBarcodeValue(`{"target": "sku"}`); value != "4006381333931" {
+		t.Fatalf("Barcode with a target should return the cell, got %q", value)
+	}
+
+	p.inTableBody = true
+	if value := p.GetBarcodeValue(`{}`); value != "4006381333931" {
+		t.Fatalf("Barcode without a source in a table should return the current cell, got %q", value)
+	}
+}
@@ -30,17 +30,84 @@ import (
 
 // SetHeaderFunc maps json to gofpdf SetHeaderFunc function. https://godoc.org/github.com/jung-kurt/gofpdf#Fpdf.SetHeaderFunc
 func (p *JSONGOFPDF) SetHeaderFunc(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
+	p.headerLogic = logic
 	pdf.SetHeaderFunc(func() {
-		pdf = p.RunArrayOperations(pdf, logic)
-		p.CurrentRowY = pdf.GetY()
-		p.CurrentY = pdf.GetY()
+		p.PageHeader(pdf)
 	})
 
 	return pdf
 }
 
-// New passes the orientation, unit, size and dir object properties to the gofpdf New function creating a new pdf
+// restorePageFuncs installs the header and footer functions of the document again, or none when none were set, after a
+// table has rendered its sections on every page.
+func (p *JSONGOFPDF) restorePageFuncs(pdf *gofpdf.Fpdf) {
+	pdf.SetHeaderFunc(nil)
+	pdf.SetFooterFunc(nil)
+	if p.headerLogic != "" {
+		p.SetHeaderFunc(pdf, p.headerLogic)
+	}
+	if p.footerLogic != "" {
+		p.SetFooterFunc(pdf, p.footerLogic)
+	}
+}
+
+// PageHeader runs at the top of every new page. It renders the operations passed to SetHeaderFunc followed by the header
+// of the table being rendered, if any, and moves the row positions below them.
+func (p *JSONGOFPDF) PageHeader(pdf *gofpdf.Fpdf) {
+	// Headers are positioned on their own page, not at the top of a row split across pages
+	split := p.rowSplit
+	p.rowSplit = nil
+	defer func() {
+		p.rowSplit = split
+	}()
+
+	if p.headerLogic != "" {
+		pdf = p.RunArrayOperations(pdf, p.headerLogic)
+		p.CurrentRowY = pdf.GetY()
+		p.CurrentY = pdf.GetY()
+		p.NextY = pdf.GetY()
+	}
+	p.pageRenderedData = nil
+	if !p.inTableSection {
+		if p.tableHeader != "" {
+			pdf = p.TableSection(pdf, p.tableHeader, 0)
+		}
+		if p.tablePageHeader != "" {
+			pdf = p.TableSection(pdf, p.tablePageHeader, 0)
+		}
+	}
+
+	if p.pageTops == nil {
+		p.pageTops = make(map[int]float64)
+	}
+	p.pageTop = pdf.GetY()
+	p.pageTops[pdf.PageNo()] = p.pageTop
+}
+
+// PageFooter runs at the end of every page. It renders the page footer of the table being rendered, if any, in the space
+// TableFunc reserves for it above the bottom margin, followed by the operations passed to SetFooterFunc.
+func (p *JSONGOFPDF) PageFooter(pdf *gofpdf.Fpdf) {
+	split := p.rowSplit
+	p.rowSplit = nil
+	defer func() {
+		p.rowSplit = split
+	}()
+
+	if p.tablePageFooter != "" && !p.inTableSection {
+		_, pageHeight := pdf.GetPageSize()
+		_, margin := pdf.GetAutoPageBreak()
+		pdf.SetY(pageHeight - margin)
+		pdf = p.TableSection(pdf, p.tablePageFooter, 0)
+	}
+	if p.footerLogic != "" {
+		pdf = p.RunArrayOperations(pdf, p.footerLogic)
+	}
+}
+
+// New passes the orientation, unit, size and dir object properties to the gofpdf New function creating a new pdf.
+// "dpi" sets the resolution of images that do not record their own, which defaults to the DPI passed to New or 96.
 func (p *JSONGOFPDF) New(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
+	p.DPI = p.GetInt("dpi", logic, p.DPI)
 	orientation := p.GetString("orientation", logic, "P")
 	unit := p.GetString("unit", logic, "mm")
 	size := p.GetString("size", logic, "A4")
@@ -134,7 +201,17 @@ func (p *JSONGOFPDF) AddPage(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf)
 // SetFont maps json to gofpdf SetFont function. Pass in "family" string, "style" string, "size" float properties in json logic.
 // Defaults are "family": "Arial", "style": "", "size", 8.0
 func (p *JSONGOFPDF) SetFont(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
-	pdf.SetFont(p.GetString("family", logic, "Arial"), p.GetString("style", logic, ""), p.GetFloat("size", logic, 8.0))
+	p.FontFamily = p.GetString("family", logic, "Arial")
+	p.FontStyle = p.GetString("style", logic, "")
+	pdf.SetFont(p.FontFamily, p.FontStyle, p.GetFloat("size", logic, 8.0))
+	return pdf
+}
+
+// SetHyphenation sets the language used to hyphenate multicell text, for example "en" or "de". Pass in "language" string
+// property in json logic, an empty language turns hyphenation off. Text operations override it with "hyphenate".
+// Default is "language": ""
+func (p *JSONGOFPDF) SetHyphenation(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
+	p.Hyphenation = p.GetString("language", logic, "")
 	return pdf
 }
 
@@ -192,14 +269,17 @@ func (p *JSONGOFPDF) Rect(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
 
 // SetFooterFunc maps json to gofpdf SetFooterFunc function. Pass in an array of operation objects to have them be executed.
 func (p *JSONGOFPDF) SetFooterFunc(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
+	p.footerLogic = logic
 	pdf.SetFooterFunc(func() {
-		pdf = p.RunArrayOperations(pdf, logic)
+		p.PageFooter(pdf)
 	})
 	return pdf
 }
 
 // CellFormat maps json to gofpdf CellFormat function. Pass in "width" float, "height" float, "border" string, "text" string, "line" int, "align" string, "fill" boolean, "link" integer, "linkstr" string
 // Defaults are "width": 0.0, "height": 0.0, "text": "", "border": "", "line": 0, "align": "L", "fill": false, "link": 0, "linkstr": ""
+// Pass "valign" as "top", "middle" or "bottom" to align the text vertically within the height of the current table row.
+// Text operations also take "style", "letterspacing" and "wordspacing", see GetTextStyle, and "fit", see FitText.
 func (p *JSONGOFPDF) CellFormat(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
 	text := p.GetString("text", logic, "")
 	text = strings.Replace(text, "<br>", "\n", -1)
@@ -226,13 +306,41 @@ func (p *JSONGOFPDF) CellFormat(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fp
 		}
 	}
 
-	pdf.CellFormat(p.GetFloat("width", logic, 0.0), p.GetFloat("height", logic, 0.0), p.tr(text), p.GetString("border", logic, ""), p.GetInt("line", logic, 0), p.GetString("align", logic, "L"), p.GetBool("fill", logic, false), p.GetInt("link", logic, 0), p.GetString("linkstr", logic, ""))
+	height := p.GetFloat("height", logic, 0.0)
+	align := p.GetString("align", logic, "L")
+	valign := p.GetString("valign", logic, "")
+	switch valign {
+	case "top":
+		align += "T"
+		break
+	case "middle":
+		align += "M"
+		break
+	case "bottom":
+		align += "B"
+		break
+	}
+	if valign != "" && p.rowHeight() > height {
+		height = p.rowHeight()
+	}
+
+	width := p.GetFloat("width", logic, 0.0)
+	textStyle := p.GetTextStyle(logic)
+	p.ApplyTextStyle(pdf, textStyle)
+	fontSize, _ := pdf.GetFontSize()
+	text, _ = p.FitText(pdf, logic, p.tr(text), width, height, false, textStyle)
+	p.SpacedCellFormat(pdf, width, height, text, p.GetString("border", logic, ""), p.GetInt("line", logic, 0), align, p.GetBool("fill", logic, false), p.GetInt("link", logic, 0), p.GetString("linkstr", logic, ""), textStyle)
+	pdf.SetFontSize(fontSize)
+	p.RestoreTextStyle(pdf, textStyle)
 	return pdf
 }
 
 // Cell maps json to gofpdf Cell function. Pass in "width" float, "height" float, "text" string object properties in json logic.
 // Defaults are "width": 0.0, "height": 0.0, "text": ""
 func (p *JSONGOFPDF) Cell(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
-	pdf.Cell(p.GetFloat("width", logic, 0.0), p.GetFloat("height", logic, 0.0), p.GetStringIndex("text", logic, ""))
+	textStyle := p.GetTextStyle(logic)
+	p.ApplyTextStyle(pdf, textStyle)
+	p.SpacedCellFormat(pdf, p.GetFloat("width", logic, 0.0), p.GetFloat("height", logic, 0.0), p.GetStringIndex("text", logic, ""), "", 0, "", false, 0, "", textStyle)
+	p.RestoreTextStyle(pdf, textStyle)
 	return pdf
 }
@@ -1,9 +1,8 @@
 package jsongofpdf
 
 import (
-	"bytes"
-	"encoding/hex"
 	"fmt"
+	"math"
 	"strings"
 
 	"github.com/buger/jsonparser"
@@ -26,17 +25,151 @@ func (p *JSONGOFPDF) UpdateY(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf)
 }
 
 // TableFunc uses json to render out a table using the passed data in the options.
+// Pass "header" as an array of operations, run against the first row of the table so "title" attributes can be used, to
+// render header rows at the start of the table and again at the top of every page the table body breaks onto.
+// Pass "pageheader" and "pagefooter" arrays of operations to render below the header at the top of every following page
+// and at the bottom of every page the table breaks from, and "footer" to render once after the last row. Calculations
+// in these sections take a "scope", see Calculation, for brought forward, carried forward and final totals.
+// Pass "groups" as an array of group levels, outermost first, see GetTableGroups, to order the rows by group and render the
+// header and footer of each group around its rows. Group sections are rendered against the first and last row of the
+// group and their calculations take the "group" scope.
+// Pass "filter" as json-logic over the row data to only render the rows it is truthy for and "sort" as an array of keys,
+// see GetTableSorts, to order the rows, within their groups when grouped. Rows and their Data are filtered and ordered
+// together for the table being rendered only.
+// Pass "keeptogether" bool to move rows that do not fit on the page to the next one and "allowsplit" bool to continue
+// rows across pages instead, with their cells side by side and the header repeated. Rows taller than a page are split
+// when kept together. Each body row can override both. Defaults are "keeptogether": true, "allowsplit": false
 func (p *JSONGOFPDF) TableFunc(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
 	p.TableIndex = p.GetInt("index", logic, 0)
+	p.renderedData = nil
+	p.pageRenderedData = nil
+
+	p.groups = p.GetTableGroups(logic)
+	if len(p.Tables) > p.TableIndex {
+		table := p.Tables[p.TableIndex]
+		p.Tables[p.TableIndex] = SortTable(FilterTable(table, p.GetString("filter", logic, "")), p.GetTableSorts(logic))
+		if len(p.groups) > 0 {
+			p.Tables[p.TableIndex], p.groupKeys = GroupTable(p.Tables[p.TableIndex], p.groups)
+		}
+		defer func(index int) {
+			p.Tables[index] = table
+			p.groups = nil
+			p.groupKeys = nil
+			p.currentGroupData = nil
+		}(p.TableIndex)
+	}
+
+	p.keepTogether = p.GetBool("keeptogether", logic, true)
+	p.allowSplit = p.GetBool("allowsplit", logic, false)
+	p.pageTop = 0
+	p.tableHeaderHeight = 0
+	header := p.GetString("header", logic, "")
+	if header != "" {
+		headerY := pdf.GetY()
+		pdf = p.TableSection(pdf, header, 0)
+		p.tableHeaderHeight = pdf.GetY() - headerY
+	}
+
+	p.tableHeader = header
+	p.tablePageHeader = p.GetString("pageheader", logic, "")
+	p.tablePageFooter = p.GetString("pagefooter", logic, "")
+	sections := p.tableHeader != "" || p.tablePageHeader != "" || p.tablePageFooter != ""
+	if sections {
+		pdf.SetHeaderFunc(func() {
+			p.PageHeader(pdf)
+		})
+		pdf.SetFooterFunc(func() {
+			p.PageFooter(pdf)
+		})
+	}
+	// Rows break above the page footer, which is drawn in the space reserved for it above the bottom margin
+	auto, margin := pdf.GetAutoPageBreak()
+	if p.tablePageFooter != "" {
+		pdf.SetAutoPageBreak(auto, margin+p.MeasureTableSection(pdf, p.tablePageFooter, 0))
+	}
 	pdf = p.Body(pdf, p.GetString("body", logic, ""))
+	pdf.SetAutoPageBreak(auto, margin)
+	if sections {
+		p.restorePageFuncs(pdf)
+	}
+	p.tableHeader = ""
+	p.tablePageHeader = ""
+	p.tablePageFooter = ""
+	p.keepTogether = true
+	p.allowSplit = false
+	p.RowHeight = 0
+	p.RowCells = 0.0
+
+	if footer := p.GetString("footer", logic, ""); footer != "" {
+		pdf = p.TableSection(pdf, footer, 0)
+	}
 	p.TableIndex = 0
 	return pdf
 }
 
+// TableSection renders the operations of a table header or footer at the current position, against the row at rowIndex.
+// The state of the row being rendered is kept, as a page can break in the middle of a row, and the row positions are
+// moved below the section.
+func (p *JSONGOFPDF) TableSection(pdf *gofpdf.Fpdf, logic string, rowIndex int) (opdf *gofpdf.Fpdf) {
+	currentRowIndex, rowHeight, rowCells := p.RowIndex, p.RowHeight, p.RowCells
+	cellIndex, cellPreIndex := p.CellIndex, p.CellPreIndex
+
+	p.inTableSection = true
+	p.RowIndex = rowIndex
+	p.RowHeight = 0
+	p.RowCells = 0.0
+	p.CellIndex = 0
+	p.CellPreIndex = 0
+	p.CurrentRowY = pdf.GetY()
+	p.NextY = pdf.GetY()
+
+	if len(p.Tables) > p.TableIndex && len(p.Tables[p.TableIndex].Rows) > rowIndex {
+		for y := 0; y < len(p.Tables[p.TableIndex].Rows[rowIndex].Cells); y++ {
+			p.PreOperations(pdf, logic)
+			p.CellPreIndex++
+		}
+		pdf = p.RunArrayOperations(pdf, logic)
+	}
+	p.inTableSection = false
+
+	y := math.Max(pdf.GetY(), p.NextY)
+	x := pdf.GetX()
+	pdf.SetXY(x, y)
+	p.CurrentRowY = y
+	p.CurrentY = y
+	p.NextY = y
+
+	p.RowIndex, p.RowHeight, p.RowCells = currentRowIndex, rowHeight, rowCells
+	p.CellIndex, p.CellPreIndex = cellIndex, cellPreIndex
+	return pdf
+}
+
+// MeasureTableSection returns the height of a table section by rendering it, against the row at rowIndex, on a template
+// that is never drawn. The row positions are left unchanged.
+func (p *JSONGOFPDF) MeasureTableSection(pdf *gofpdf.Fpdf, logic string, rowIndex int) (height float64) {
+	currentRowY, currentY, nextY := p.CurrentRowY, p.CurrentY, p.NextY
+	left, top, right, _ := pdf.GetMargins()
+	x := pdf.GetX()
+	pdf.CreateTemplate(func(tpl *gofpdf.Tpl) {
+		measure := &tpl.Fpdf
+		measure.SetAutoPageBreak(false, 0)
+		measure.SetMargins(left, top, right)
+		measure.SetCellMargin(pdf.GetCellMargin())
+		measure.SetXY(x, top)
+		measure = p.TableSection(measure, logic, rowIndex)
+		height = measure.GetY() - top
+	})
+	p.CurrentRowY, p.CurrentY, p.NextY = currentRowY, currentY, nextY
+	return height
+}
+
 // Body iterates over the table rows and renders the table based on the passed operations.
+// Each row object can pass "keeptogether" and "allowsplit" bool to override those of the table, see TableFunc.
 func (p *JSONGOFPDF) Body(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
 	// We store the logic of each row logic so we can alternate between each
 	rowLogic := make([]string, 0)
+	keepTogether := make([]bool, 0)
+	allowSplit := make([]bool, 0)
 	jsonparser.ArrayEach([]byte(logic), func(value []byte, dataType jsonparser.ValueType, offset int, err error) {
 		switch dataType {
 		case jsonparser.Object:
@@ -44,6 +177,8 @@ func (p *JSONGOFPDF) Body(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
 			operations := p.GetString("row", string(value), "")
 			if operations != "" {
 				rowLogic = append(rowLogic, operations)
+				keepTogether = append(keepTogether, p.GetBool("keeptogether", string(value), p.keepTogether))
+				allowSplit = append(allowSplit, p.GetBool("allowsplit", string(value), p.allowSplit))
 			}
 			break
 		}
@@ -51,8 +186,15 @@ func (p *JSONGOFPDF) Body(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
 
 	// Then foreach table.rows we can alternate between each function using RowIndex which resets on each new table.row
 	if len(rowLogic) > 0 {
+		p.inTableBody = true
+		defer func() {
+			p.inTableBody = false
+		}()
 		rowLength := len(p.Tables[p.TableIndex].Rows)
 		for x := 0; x < rowLength; x++ {
+			if len(p.groups) > 0 {
+				pdf = p.GroupSections(pdf, x)
+			}
 
 			p.RowIndex = x
 			p.RowHeight = 0
@@ -65,7 +207,15 @@ func (p *JSONGOFPDF) Body(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
 				p.CellPreIndex++
 			}
 
-			pdf = p.RunArrayOperations(pdf, rowLogic[p.RowFuncIndex])
+			if p.RowBreak(pdf, keepTogether[p.RowFuncIndex], allowSplit[p.RowFuncIndex]) {
+				pdf = p.SplitRow(pdf, rowLogic[p.RowFuncIndex])
+			} else {
+				pdf = p.RunArrayOperations(pdf, rowLogic[p.RowFuncIndex])
+			}
+			if len(p.Tables[p.TableIndex].Data) > x {
+				p.renderedData = append(p.renderedData, p.Tables[p.TableIndex].Data[x])
+				p.pageRenderedData = append(p.pageRenderedData, p.Tables[p.TableIndex].Data[x])
+			}
 
 			if (p.RowFuncIndex + 1) < len(rowLogic) {
 				p.RowFuncIndex++
@@ -77,13 +227,63 @@ func (p *JSONGOFPDF) Body(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
 			}
 			p.CurrentRowY = pdf.GetY()
 		}
+		if len(p.groups) > 0 && rowLength > 0 {
+			pdf = p.GroupSections(pdf, rowLength)
+		}
 	}
 
 	return pdf
 }
 
+// GroupSections renders the group footers of the previous row and the group headers of the row at rowIndex for every
+// group level that changes between them, innermost footer first. Pass the number of rows to close the last groups.
+func (p *JSONGOFPDF) GroupSections(pdf *gofpdf.Fpdf, rowIndex int) (opdf *gofpdf.Fpdf) {
+	rowLength := len(p.Tables[p.TableIndex].Rows)
+	level := 0
+	if rowIndex < rowLength {
+		level = p.groupLevel(rowIndex)
+	}
+
+	if rowIndex > 0 {
+		for index := len(p.groups) - 1; index >= level; index-- {
+			if p.groups[index].Footer != "" {
+				p.currentGroupData = p.groupData(index, rowIndex-1)
+				pdf = p.groupSection(pdf, p.groups[index].Footer, rowIndex-1)
+			}
+		}
+	}
+	if rowIndex < rowLength {
+		for index := level; index < len(p.groups); index++ {
+			if p.groups[index].Header != "" {
+				p.currentGroupData = p.groupData(index, rowIndex)
+				pdf = p.groupSection(pdf, p.groups[index].Header, rowIndex)
+			}
+		}
+		p.currentGroupData = p.groupData(len(p.groups)-1, rowIndex)
+	}
+	return pdf
+}
+
+// groupSection renders a group header or footer as a table section, moving it to the next page when it does not fit on
+// this one so the page breaks between rows with the table sections of both pages rendered.
+func (p *JSONGOFPDF) groupSection(pdf *gofpdf.Fpdf, logic string, rowIndex int) (opdf *gofpdf.Fpdf) {
+	if p.PageBreak(pdf, p.MeasureTableSection(pdf, logic, rowIndex)) {
+		p.CurrentRowY = pdf.GetY()
+	}
+	return p.TableSection(pdf, logic, rowIndex)
+}
+
+// Image maps json to gofpdf ImageOptions function. Pass in "src" string as a base64 data uri, a name in the Images or
+// ImageProvider passed to New or a file path, or "data" string as a dotted path to an image stored in the bound Data.
+// Images are registered once by "name", which defaults to the src or data path, and reused by later operations.
+// Pass "x", "y", "width", "height" float, "flow" bool, "link" int, "linkstr" string properties in json logic.
+// Pass "fit" string as "contain", "cover", "fill" or "none" to scale the image into the width and height box, positioned by
+// "align" string "L", "C", "R" and "valign" string "T", "M", "B". With "flow" the box is placed at the current y.
+// Defaults are "src": "", "data": "", "name": "", "x": 0.0, "y": 0.0, "width": 0.0, "height": 0.0, "flow": false, "link": -1, "linkstr": "",
+// "fit": "", "align": "L", "valign": "T"
 func (p *JSONGOFPDF) Image(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
 	src := p.GetString("src", logic, "")
+	dataPath := p.GetString("data", logic, "")
 	name := p.GetString("name", logic, "")
 	x := p.GetFloat("x", logic, 0.0)
 	y := p.GetFloat("y", logic, 0.0)
@@ -92,19 +292,75 @@ func (p *JSONGOFPDF) Image(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
 	flow := p.GetBool("flow", logic, false)
 	link := p.GetInt("link", logic, -1)
 	linkStr := p.GetString("linkstr", logic, "")
+	fit := p.GetString("fit", logic, "")
+	align := p.GetString("align", logic, "L")
+	valign := p.GetString("valign", logic, "T")
 
-	image, err := GetImage(src)
-	if err == nil {
-		imageContent := strings.TrimPrefix(image.Data, "0x")
-		imageDecoded, err := hex.DecodeString(imageContent)
+	if name == "" {
+		name = src
+		if dataPath != "" {
+			name = "data:" + dataPath
+		}
+	}
+	name = p.placedImageName(name, width, height)
+
+	svg := p.svgs[name]
+	if svg == nil && pdf.GetImageInfo(name) == nil {
+		var data []byte
+		var err error
+		if dataPath != "" {
+			data, err = p.GetDataImage(dataPath)
+		} else {
+			data, err = p.GetImageData(src)
+		}
 		if err == nil {
-			options := gofpdf.ImageOptions{
-				ReadDpi:   false,
-				ImageType: image.Type,
+			if IsSVG(data) {
+				svg, err = p.RegisterSVG(name, data)
+			} else {
+				_, err = p.RegisterImage(pdf, name, data, width, height)
 			}
-			pdf.RegisterImageOptionsReader(name, options, bytes.NewReader(imageDecoded))
-			pdf.ImageOptions(name, x, y, width, height, flow, options, link, linkStr)
 		}
+		if err != nil {
+			fmt.Println(err)
+			return pdf
+		}
+	}
+
+	// SVGs are drawn as vector graphics, stretched to the box like images unless a fit is given
+	if svg != nil {
+		if fit == "" {
+			fit = "fill"
+		}
+		if flow {
+			y = pdf.GetY()
+		}
+		boxWidth, boxHeight := p.DrawSVG(pdf, svg, x, y, width, height, fit, align, valign, "", "")
+		if link > 0 {
+			pdf.Link(x, y, boxWidth, boxHeight, link)
+		} else if linkStr != "" {
+			pdf.LinkString(x, y, boxWidth, boxHeight, linkStr)
+		}
+		if flow {
+			pdf.SetY(y + boxHeight)
+		}
+		return pdf
+	}
+
+	if fit == "" {
+		// gofpdf sizes images given no width and height at 96 dpi unless asked for the image resolution with -1
+		if width == 0 && height == 0 {
+			width, height = -1, -1
+		}
+		pdf.ImageOptions(name, x, y, width, height, flow, gofpdf.ImageOptions{}, link, linkStr)
+		return pdf
+	}
+
+	if flow {
+		y = pdf.GetY()
+	}
+	_, boxHeight := p.FitImage(pdf, name, x, y, width, height, fit, align, valign, link, linkStr)
+	if flow {
+		pdf.SetY(y + boxHeight)
 	}
 
 	return pdf
@@ -116,9 +372,18 @@ func (p *JSONGOFPDF) SetXCurrentX(pdf *gofpdf.Fpdf) (opdf *gofpdf.Fpdf) {
 	return pdf
 }
 
-// RowY sets pdf Y to CurrentRowY position
+// rowHeight returns the height measured for the table row or section being rendered, which cells grow to when aligned
+// vertically, or 0 outside of a table.
+func (p *JSONGOFPDF) rowHeight() float64 {
+	if !p.inTableBody && !p.inTableSection {
+		return 0
+	}
+	return p.RowHeight
+}
+
+// RowY sets pdf Y to CurrentRowY position, on the page the row started on when it is split across pages
 func (p *JSONGOFPDF) RowY(pdf *gofpdf.Fpdf) (opdf *gofpdf.Fpdf) {
-	pdf.SetY(p.CurrentRowY)
+	p.rowSplitStart(pdf)
 	return pdf
 }
 
@@ -163,6 +428,15 @@ func (p *JSONGOFPDF) SetInitY(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf
 	return pdf
 }
 
+// MultiCell renders text, a table cell or a global with gofpdf MultiCell, padding it to the row height calculated by PreRowMultiCell.
+// Pass "valign" as "top", "middle" or "bottom" to position the text within the row height instead of padding below it.
+// Cell images are clamped to the cell width unless "imagefit" is given, which fits them into the cell width by "imageheight"
+// box the same way the image "fit", "align" and "valign" properties do.
+// Pass "imagecolumns" int to lay the images out below the text as a gallery grid, counted in the row height, with "imagegap"
+// float between images, "imagemaxheight" float limiting their height and "imagecaption" string "name", "created" or "both"
+// writing captions under each image with the date formatted by "imagedateformat" string. Gallery images are fitted by
+// "imagefit" into their column, defaulting to "contain" centred with "imagealign" "C" and "imagevalign" "M".
+// Gallery defaults are "imagecolumns": 0, "imagegap": 2.0, "imagemaxheight": 0.0, "imagecaption": "", "imagedateformat": "d/M/yyyy"
 func (p *JSONGOFPDF) MultiCell(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
 	attribute := p.GetString("attribute", logic, "")
 	target := p.GetString("target", logic, "")
@@ -175,56 +449,13 @@ func (p *JSONGOFPDF) MultiCell(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpd
 	text := p.GetString("text", logic, "")
 	fill := p.GetBool("fill", logic, false)
 	format := p.GetString("format", logic, "")
+	valign := p.GetString("valign", logic, "top")
 
 	if v := p.GetString("calculation", logic, ""); v != "" {
 		text = p.Calculation(v, text)
 	}
 
-	cell := Cell{}
-
-	if len(p.Tables) > p.TableIndex {
-		if len(p.Tables[p.TableIndex].Rows) > p.RowIndex {
-			if target == "" {
-				cell = p.Tables[p.TableIndex].Rows[p.RowIndex].Cells[p.CellIndex]
-			}
-			for _, rowCell := range p.Tables[p.TableIndex].Rows[p.RowIndex].Cells {
-				if rowCell.Key == target || rowCell.Path == target {
-					cell = rowCell
-				}
-			}
-		}
-	}
-
-	if loop {
-		for _, table := range p.Tables {
-			for _, row := range table.Rows {
-				for _, rowCell := range row.Cells {
-					if rowCell.Key == target || rowCell.Path == target {
-						cell = rowCell
-					}
-				}
-			}
-		}
-	}
-
-	// fmt.Println(cell)
-
-	if text != "" {
-		cell = Cell{
-			Value: text,
-		}
-	}
-
-	for index, value := range p.Globals {
-		if index == target {
-			cell = Cell{
-				Path:  index,
-				Key:   index,
-				Title: index,
-				Value: value,
-			}
-		}
-	}
+	cell := p.GetCell(target, text, loop)
 
 	cellCount := 0.0
 	cellX := pdf.GetX()
@@ -250,49 +481,106 @@ func (p *JSONGOFPDF) MultiCell(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpd
 		renderText = p.Format(format, renderText)
 	}
 
-	renderText = p.tr(strings.Replace(renderText, "<br>", "\n", -1))
-	cellList := pdf.SplitLines([]byte(renderText), width)
-	cellCount = float64(len(cellList))
+	textStyle := p.GetTextStyle(logic)
+	p.ApplyTextStyle(pdf, textStyle)
 
-	if renderText != "" {
-		pdf.MultiCell(width, height, renderText, border, align, fill)
+	renderText = p.tr(strings.Replace(renderText, "<br>", "\n", -1))
+	fontSize, _ := pdf.GetFontSize()
+	renderText, lineHeight := p.FitText(pdf, logic, renderText, width, height, true, textStyle)
+	cellList := p.SplitText(pdf, renderText, width, textStyle)
+	textHeight := float64(len(cellList)) * lineHeight
+
+	// A gallery is drawn below the text within the row rather than after it
+	gallery := p.GetGallery(pdf, logic)
+	if cell.Images == nil || attribute != "value" {
+		gallery = nil
+	}
+	galleryHeight := 0.0
+	if gallery != nil {
+		galleryHeight = p.GalleryHeight(pdf, cell.Images, width, gallery)
 	}
+	cellCount = math.Ceil((textHeight+galleryHeight)/height - 0.000001)
+
+	if rowHeight := p.rowHeight(); valign != "top" && rowHeight > textHeight+galleryHeight {
+		// Draw the box at full row height and position the text inside it rather than padding with blank lines
+		pdf.CellFormat(width, rowHeight, "", border, 0, "", fill, 0, "")
+		cellY := pdf.GetY()
+		offset := rowHeight - textHeight - galleryHeight
+		if valign == "middle" {
+			offset = offset / 2
+		}
+		pdf.SetXY(cellX, cellY+offset)
+		if renderText != "" {
+			p.SpacedMultiCell(pdf, width, lineHeight, renderText, "", align, false, textStyle)
+		}
+		if gallery != nil {
+			pdf.SetY(cellY + offset + textHeight)
+			p.DrawGallery(pdf, cell.Images, cellX, width, gallery, "", false)
+		}
+		pdf.SetY(cellY + rowHeight)
+		pdf.SetFontSize(fontSize)
+		p.RestoreTextStyle(pdf, textStyle)
+	} else {
+		if renderText != "" {
+			p.SpacedMultiCell(pdf, width, lineHeight, renderText, border, align, fill, textStyle)
+		}
+
+		pdf.SetFontSize(fontSize)
+		p.RestoreTextStyle(pdf, textStyle)
 
-	// }
+		if gallery != nil {
+			p.DrawGallery(pdf, cell.Images, cellX, width, gallery, border, fill)
+		}
 
-	if cellCount < p.RowCells {
-		for i := 0; i < int(p.RowCells-cellCount); i++ {
-			pdf.SetX(cellX)
-			pdf.MultiCell(width, height, "", border, align, fill)
+		if cellCount < p.RowCells {
+			for i := 0; i < int(p.RowCells-cellCount); i++ {
+				pdf.SetX(cellX)
+				pdf.MultiCell(width, height, "", border, align, fill)
+			}
 		}
 	}
 
-	if cell.Images != nil && attribute == "value" {
+	if cell.Images != nil && attribute == "value" && gallery == nil {
+		imageFit := p.GetString("imagefit", logic, "")
+		imageAlign := p.GetString("imagealign", logic, "L")
+		imageVAlign := p.GetString("imagevalign", logic, "T")
+		imageBoxHeight := p.GetFloat("imageheight", logic, 0.0)
 		for _, image := range cell.Images {
+			// For any media against the field, reusing images already registered under the same name
+			name := p.placedImageName(cellImageName(image), width, imageBoxHeight)
+			info, svg, err := p.RegisterCellImage(pdf, name, image, width, imageBoxHeight)
+			if err != nil {
+				fmt.Println(err)
+				continue
+			}
 
-			// For any media against the field
-			imageDecoded, err := hex.DecodeString(image.Data)
-			if err == nil {
-				options := gofpdf.ImageOptions{
-					ReadDpi:   false,
-					ImageType: image.Type,
+			if svg != nil {
+				svgFit := imageFit
+				if svgFit == "" {
+					svgFit = "contain"
 				}
+				imageY := pdf.GetY()
+				_, boxHeight := p.DrawSVG(pdf, svg, cellX, imageY, width, imageBoxHeight, svgFit, imageAlign, imageVAlign, "", "")
+				pdf.SetY(imageY + boxHeight)
+				continue
+			}
 
-				imageWidth := float64(image.Width) / float64(p.DPI)
-				imageHeight := float64(image.Height) / float64(p.DPI)
+			if imageFit != "" {
+				imageY := pdf.GetY()
+				_, boxHeight := p.FitImage(pdf, name, cellX, imageY, width, imageBoxHeight, imageFit, imageAlign, imageVAlign, 0, "")
+				pdf.SetY(imageY + boxHeight)
+				continue
+			}
 
-				if imageWidth > width {
-					imageWidth = width
-					imageHeight = 0
-				}
+			// The natural size in document units follows the image resolution
+			imageWidth, imageHeight := info.Extent()
 
-				// Pass binary image into PDF
-				pdf.RegisterImageOptionsReader(string(p.MediaIndex), options, bytes.NewReader(imageDecoded))
-				pdf.ImageOptions(string(p.MediaIndex), cellX, pdf.GetY(), imageWidth, imageHeight, true, options, -1, "")
-				p.MediaIndex++
-			} else {
-				fmt.Println(err)
+			if imageWidth > width {
+				imageWidth = width
+				imageHeight = 0
 			}
+
+			pdf.ImageOptions(name, cellX, pdf.GetY(), imageWidth, imageHeight, true, gofpdf.ImageOptions{}, -1, "")
 		}
 	}
 
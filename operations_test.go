@@ -0,0 +1,186 @@
+package jsongofpdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// pageText is a string drawn on a page with its start from the left and its baseline from the top of the page in mm.
+type pageText struct {
+	x    float64
+	y    float64
+	text string
+}
+
+var pageTextRe = regexp.MustCompile(`BT ([\d.-]+) ([\d.-]+) Td \((.*?)\) ?Tj ET`)
+
+// renderStreams renders logic over tables and returns the uncompressed content stream of each page.
+func renderStreams(t *testing.T, logic string, tables []Table) (streams []string, pdf *gofpdf.Fpdf) {
+	p, _ := New(JSONGOFPDFOptions{Logic: logic, Tables: tables})
+	pdf = p.GetPDF()
+	pdf.SetCompression(false)
+	var buffer bytes.Buffer
+	if err := pdf.Output(&buffer); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, stream := range regexp.MustCompile(`(?s)stream\n(.*?)\nendstream`).FindAllStringSubmatch(buffer.String(), pdf.PageCount()) {
+		streams = append(streams, stream[1])
+	}
+	return streams, pdf
+}
+
+// renderPages renders logic over tables on A4 pages in mm and returns the text drawn on each page.
+func renderPages(t *testing.T, logic string, tables []Table) (pages [][]pageText) {
+	streams, pdf := renderStreams(t, logic, tables)
+	_, pageHeight := pdf.GetPageSize()
+	for _, stream := range streams {
+		page := make([]pageText, 0)
+		for _, match := range pageTextRe.FindAllStringSubmatch(stream, -1) {
+			x, _ := strconv.ParseFloat(match[1], 64)
+			y, _ := strconv.ParseFloat(match[2], 64)
+			page = append(page, pageText{x: x / pdf.GetConversionRatio(), y: pageHeight - y/pdf.GetConversionRatio(), text: match[3]})
+		}
+		pages = append(pages, page)
+	}
+	return pages
+}
+
+// amountTable is a table of count rows, each with an item and amount cell and the amount in its data.
+func amountTable(count int) (table Table) {
+	for row := 1; row <= count; row++ {
+		table.Rows = append(table.Rows, Row{Cells: []Cell{
+			{Key: "item", Path: "item", Title: "Item", Value: fmt.Sprintf("item %d", row)},
+			{Key: "amount", Path: "amount", Title: "Amount", Value: row},
+		}})
+		table.Data = append(table.Data, fmt.Sprintf(`{"amount":%d,"group":%d}`, row, (row-1)/10))
+	}
+	return table
+}
+
+const amountRow = `[{"rowy": {}}, {"setx": {"x": 10}}, {"multicell": {"attribute": "value", "target": "item", "width": 40, "height": 5, "border": "1"}},
+	{"rowy": {}}, {"setx": {"x": 50}}, {"multicell": {"attribute": "value", "target": "amount", "width": 40, "height": 5, "border": "1"}}, {"sety": {"auto": "P"}}]`
+
+const amountHeader = `[{"setx": {"x": 10}}, {"cellformat": {"text": "Item", "width": 40, "height": 6, "border": "1"}},
+	{"cellformat": {"text": "Amount", "width": 40, "height": 6, "border": "1", "line": 1}}]`
+
+func TestTableFuncHeader(t *testing.T) {
+	logic := `[{"new": {}}, {"setfont": {"size": 10}}, {"addpage": {}}, {"tablefunc": {"header": ` + amountHeader + `,
+		"body": [{"row": ` + amountRow + `}]}}]`
+	pages := renderPages(t, logic, []Table{amountTable(120)})
+	if len(pages) < 3 {
+		t.Fatalf("120 rows should break onto at least 3 pages, got %d", len(pages))
+	}
+
+	next := 1
+	for index, page := range pages {
+		if len(page) < 2 || page[0].text != "Item" || page[1].text != "Amount" {
+			t.Fatalf("page %d should start with the header, got %v", index+1, page)
+		}
+		for _, text := range page[2:] {
+			if text.y < page[0].y {
+				t.Fatalf("page %d has %q above the header", index+1, text.text)
+			}
+			if strings.HasPrefix(text.text, "item ") {
+				if text.text != fmt.Sprintf("item %d", next) {
+					t.Fatalf("page %d should continue with item %d, got %q", index+1, next, text.text)
+				}
+				next++
+			}
+		}
+	}
+	if next != 121 {
+		t.Fatalf("every row should be rendered once, got %d rows", next-1)
+	}
+}
+
+// pageValue returns the number drawn after the first label on a page, or -1 when the label is not on the page.
+func pageValue(page []pageText, label string) (value int, y float64) {
+	for index, text := range page[:len(page)-1] {
+		if text.text == label {
+			value, _ = strconv.Atoi(page[index+1].text)
+			return value, text.y
+		}
+	}
+	return -1, 0
+}
+
+func TestTableFuncCalculationScopes(t *testing.T) {
+	section := func(label string, scope string) string {
+		return `[{"setx": {"x": 10}}, {"cellformat": {"text": "` + label + `", "width": 40, "height": 6}},
+			{"cellformat": {"calculation": {"type": "sum", "formula": {"var": "amount"}, "scope": "` + scope + `"}, "width": 40, "height": 6, "line": 1}}]`
+	}
+	pageFooter := `[{"setx": {"x": 10}}, {"cellformat": {"text": "Page", "width": 20, "height": 6}},
+		{"cellformat": {"calculation": {"type": "sum", "formula": {"var": "amount"}, "scope": "page"}, "width": 20, "height": 6}},
+		{"cellformat": {"text": "Carried", "width": 20, "height": 6}},
+		{"cellformat": {"calculation": {"type": "sum", "formula": {"var": "amount"}, "scope": "rendered"}, "width": 20, "height": 6, "line": 1}}]`
+	logic := `[{"new": {}}, {"setfont": {"size": 10}}, {"addpage": {}}, {"tablefunc": {"header": ` + amountHeader + `,
+		"pageheader": ` + section("Brought", "rendered") + `, "pagefooter": ` + pageFooter + `, "footer": ` + section("Total", "rendered") + `,
+		"groups": [{"key": "group", "footer": ` + section("Group", "group") + `}], "body": [{"row": ` + amountRow + `}]}}, {"addpage": {}}]`
+	pages := renderPages(t, logic, []Table{amountTable(120)})
+	if len(pages) < 4 {
+		t.Fatalf("120 rows should break onto at least 3 pages, got %d", len(pages)-1)
+	}
+	if after := pages[len(pages)-1]; len(after) > 0 {
+		t.Fatalf("the page after the table should have no table sections, got %v", after)
+	}
+	pages = pages[:len(pages)-1]
+
+	carried, groups := 0, 0
+	for index, page := range pages {
+		pageSum, lastRowY := 0, 0.0
+		for position, text := range page {
+			if strings.HasPrefix(text.text, "item ") {
+				item, _ := strconv.Atoi(strings.TrimPrefix(text.text, "item "))
+				pageSum += item
+				lastRowY = text.y
+			}
+			if text.text == "Group" {
+				if value, _ := pageValue(page[position:], "Group"); value != 100*groups+55 {
+					t.Fatalf("group %d should total %d, got %d", groups+1, 100*groups+55, value)
+				}
+				groups++
+			}
+		}
+		if brought, _ := pageValue(page, "Brought"); index > 0 && brought != carried {
+			t.Fatalf("page %d should bring forward %d, got %d", index+1, carried, brought)
+		}
+		carried += pageSum
+		if index == len(pages)-1 {
+			if total, _ := pageValue(page, "Total"); total != 7260 {
+				t.Fatalf("the table total should be 7260, got %d", total)
+			}
+			if groups != 12 {
+				t.Fatalf("every group should have a footer, got %d", groups)
+			}
+			break
+		}
+
+		value, footerY := pageValue(page, "Page")
+		if value != pageSum {
+			t.Fatalf("page %d should total %d, got %d", index+1, pageSum, value)
+		}
+		if value, _ := pageValue(page, "Carried"); value != carried {
+			t.Fatalf("page %d should carry forward %d, got %d", index+1, carried, value)
+		}
+		if footerY < lastRowY || footerY > 297-20 {
+			t.Fatalf("page %d footer at %.1fmm should be below the last row at %.1fmm and above the bottom margin", index+1, footerY, lastRowY)
+		}
+	}
+}
+
+func TestTableFuncRowHeightReset(t *testing.T) {
+	logic := `[{"new": {}}, {"setfont": {"size": 10}}, {"addpage": {}}, {"tablefunc": {"body": [{"row": ` + amountRow + `}]}},
+		{"sety": {"y": 200}}, {"setx": {"x": 10}}, {"cellformat": {"text": "after", "width": 40, "height": 6, "valign": "middle"}}]`
+	pages := renderPages(t, logic, []Table{linesTable(8)})
+	last := pages[len(pages)-1]
+	if text := last[len(last)-1]; text.text != "after" || text.y < 200 || text.y > 206 {
+		t.Fatalf("a cell after a table should not grow to the height of its last row, got %q at %.1fmm", text.text, text.y)
+	}
+}
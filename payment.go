@@ -0,0 +1,429 @@
+package jsongofpdf
+
+import (
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/spf13/cast"
+)
+
+var (
+	ErrIBAN               = errors.New("IBAN checksum is invalid")
+	ErrIBANCountry        = errors.New("IBAN must be a Swiss or Liechtenstein IBAN")
+	ErrQRReference        = errors.New("QR reference must be 27 digits with a valid check digit")
+	ErrCreditorReference  = errors.New("Creditor reference must be an RF reference with a valid checksum")
+	ErrReferenceType      = errors.New("QR-IBANs need a QR reference and other IBANs a creditor reference or none")
+	ErrPaymentName        = errors.New("Payment name is missing or too long")
+	ErrPaymentAddress     = errors.New("Creditor address needs a postcode, town and country")
+	ErrPaymentAmount      = errors.New("Payment amount is out of range")
+	ErrPaymentCurrency    = errors.New("Swiss QR-bills must be in CHF or EUR")
+	ErrPaymentBIC         = errors.New("BIC must be 8 or 11 characters")
+	ErrPaymentInformation = errors.New("Payment remittance information is too long")
+	ErrPaymentReference   = errors.New("Payment can have a reference or remittance information but not both")
+	ErrPaymentPurpose     = errors.New("Payment purpose must be a code of up to 4 letters")
+	ErrPaymentNote        = errors.New("Payment information to the beneficiary is too long")
+	ErrPaymentPayload     = errors.New("EPC payload must not be longer than 331 bytes")
+)
+
+// PaymentErrors are all the errors found validating a payment.
+type PaymentErrors []error
+
+// Error joins the messages of the errors.
+func (e PaymentErrors) Error() string {
+	messages := make([]string, len(e))
+	for index, err := range e {
+		messages[index] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// QRAddress is a structured party address of a payment QR code.
+type QRAddress struct {
+	Name           string
+	Street         string
+	BuildingNumber string
+	PostCode       string
+	Town           string
+	Country        string
+}
+
+// IsEmpty reports whether no part of the address is set.
+func (a QRAddress) IsEmpty() bool {
+	return a == QRAddress{}
+}
+
+// lines are the Swiss QR code address fields, structured address type "S" or empty fields when not set.
+func (a QRAddress) lines() []string {
+	if a.IsEmpty() {
+		return make([]string, 7)
+	}
+	return []string{"S", a.Name, a.Street, a.BuildingNumber, a.PostCode, a.Town, strings.ToUpper(a.Country)}
+}
+
+// EPCPayment is a SEPA credit transfer in the European Payments Council QR code format, also known as GiroCode.
+type EPCPayment struct {
+	BIC         string
+	Name        string
+	IBAN        string
+	Amount      float64
+	Purpose     string
+	Reference   string
+	Remittance  string
+	Information string
+}
+
+// Payload returns the EPC QR code content, version 002 in UTF-8. Only one of Reference and Remittance may be given, which
+// Validate checks, the structured creditor reference is used when both are.
+func (e EPCPayment) Payload() string {
+	amount := ""
+	if e.Amount > 0 {
+		amount = "EUR" + strconv.FormatFloat(e.Amount, 'f', 2, 64)
+	}
+	remittance := e.Remittance
+	if e.Reference != "" {
+		remittance = ""
+	}
+	lines := []string{"BCD", "002", "1", "SCT", e.BIC, e.Name, compactIBAN(e.IBAN), amount, e.Purpose, e.Reference, remittance, e.Information}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+// SwissQRBill is the payment part of a Swiss QR-bill. ReferenceType is "QRR" for a QR reference, "SCOR" for a creditor
+// reference or "NON", chosen from the reference when empty. An Amount of 0 leaves the amount to the payer.
+type SwissQRBill struct {
+	IBAN            string
+	Creditor        QRAddress
+	Amount          float64
+	Currency        string
+	Debtor          QRAddress
+	ReferenceType   string
+	Reference       string
+	Message         string
+	BillInformation string
+}
+
+// Payload returns the Swiss QR code content, version 0200 with UTF-8 coding.
+func (s SwissQRBill) Payload() string {
+	amount := ""
+	if s.Amount > 0 {
+		amount = strconv.FormatFloat(s.Amount, 'f', 2, 64)
+	}
+	currency := strings.ToUpper(s.Currency)
+	if currency == "" {
+		currency = "CHF"
+	}
+
+	lines := []string{"SPC", "0200", "1", compactIBAN(s.IBAN)}
+	lines = append(lines, s.Creditor.lines()...)
+	// The ultimate creditor is reserved for future use
+	lines = append(lines, make([]string, 7)...)
+	lines = append(lines, amount, currency)
+	lines = append(lines, s.Debtor.lines()...)
+	lines = append(lines, s.referenceType(), strings.Replace(s.Reference, " ", "", -1), s.Message, "EPD")
+	if s.BillInformation != "" {
+		lines = append(lines, s.BillInformation)
+	}
+	return strings.Join(lines, "\r\n")
+}
+
+// referenceType returns ReferenceType or the type of Reference, 27 digit QR references and RF creditor references.
+func (s SwissQRBill) referenceType() string {
+	if s.ReferenceType != "" {
+		return strings.ToUpper(s.ReferenceType)
+	}
+	reference := strings.Replace(s.Reference, " ", "", -1)
+	if reference == "" {
+		return "NON"
+	}
+	if strings.HasPrefix(strings.ToUpper(reference), "RF") {
+		return "SCOR"
+	}
+	return "QRR"
+}
+
+// Validate checks the IBAN, that Swiss QR-bills go to a Swiss or Liechtenstein account, the creditor, amount and currency,
+// and that the reference matches both its type and the kind of IBAN.
+func (s SwissQRBill) Validate() error {
+	iban := compactIBAN(s.IBAN)
+	if err := ValidateIBAN(iban); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(iban, "CH") && !strings.HasPrefix(iban, "LI") {
+		return ErrIBANCountry
+	}
+	if s.Creditor.Name == "" || utf8.RuneCountInString(s.Creditor.Name) > 70 {
+		return ErrPaymentName
+	}
+	if s.Creditor.PostCode == "" || s.Creditor.Town == "" || len(s.Creditor.Country) != 2 {
+		return ErrPaymentAddress
+	}
+	if s.Amount < 0 || s.Amount > 999999999.99 {
+		return ErrPaymentAmount
+	}
+	if currency := strings.ToUpper(s.Currency); currency != "" && currency != "CHF" && currency != "EUR" {
+		return ErrPaymentCurrency
+	}
+	if utf8.RuneCountInString(s.Message)+utf8.RuneCountInString(s.BillInformation) > 140 {
+		return ErrPaymentInformation
+	}
+
+	reference := strings.Replace(s.Reference, " ", "", -1)
+	switch s.referenceType() {
+	case "QRR":
+		if !IsQRIBAN(iban) {
+			return ErrReferenceType
+		}
+		return ValidateQRReference(reference)
+	case "SCOR":
+		if IsQRIBAN(iban) {
+			return ErrReferenceType
+		}
+		return ValidateCreditorReference(reference)
+	case "NON":
+		if IsQRIBAN(iban) || reference != "" {
+			return ErrReferenceType
+		}
+		return nil
+	}
+	return ErrReferenceType
+}
+
+// Validate checks the IBAN, BIC, beneficiary name, amount, purpose, reference, remittance and information limits of the
+// EPC format and the length of its payload, returning PaymentErrors with every field that fails.
+func (e EPCPayment) Validate() error {
+	errs := PaymentErrors{}
+	if err := ValidateIBAN(e.IBAN); err != nil {
+		errs = append(errs, err)
+	}
+	if bic := strings.TrimSpace(e.BIC); bic != "" && len(bic) != 8 && len(bic) != 11 {
+		errs = append(errs, ErrPaymentBIC)
+	}
+	if e.Name == "" || utf8.RuneCountInString(e.Name) > 70 {
+		errs = append(errs, ErrPaymentName)
+	}
+	if e.Amount < 0 || e.Amount > 999999999.99 {
+		errs = append(errs, ErrPaymentAmount)
+	}
+	if len(e.Purpose) > 4 || !isAlphanumeric(e.Purpose) {
+		errs = append(errs, ErrPaymentPurpose)
+	}
+	if e.Reference != "" && e.Remittance != "" {
+		errs = append(errs, ErrPaymentReference)
+	}
+	if e.Reference != "" {
+		if err := ValidateCreditorReference(e.Reference); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if utf8.RuneCountInString(e.Remittance) > 140 {
+		errs = append(errs, ErrPaymentInformation)
+	}
+	if utf8.RuneCountInString(e.Information) > 70 {
+		errs = append(errs, ErrPaymentNote)
+	}
+	if len(e.Payload()) > 331 {
+		errs = append(errs, ErrPaymentPayload)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// ValidateIBAN checks the length for its country and ISO 13616 mod 97 checksum of an IBAN, printed with or without spaces.
+func ValidateIBAN(iban string) error {
+	iban = compactIBAN(iban)
+	if len(iban) < 15 || len(iban) > 34 || !isAlphanumeric(iban) {
+		return ErrIBAN
+	}
+	if length, ok := ibanLengths[iban[:2]]; ok && len(iban) != length {
+		return ErrIBAN
+	}
+	if mod97(iban[4:]+iban[:4]) != 1 {
+		return ErrIBAN
+	}
+	return nil
+}
+
+// ibanLengths are the IBAN lengths of the countries in the ISO 13616 registry, others are only checked to be 15 to 34.
+var ibanLengths = map[string]int{
+	"AD": 24, "AE": 23, "AL": 28, "AT": 20, "AZ": 28, "BA": 20, "BE": 16, "BG": 22, "BH": 22, "BR": 29, "BY": 28,
+	"CH": 21, "CR": 22, "CY": 28, "CZ": 24, "DE": 22, "DK": 18, "DO": 28, "EE": 20, "EG": 29, "ES": 24, "FI": 18,
+	"FO": 18, "FR": 27, "GB": 22, "GE": 22, "GI": 23, "GL": 18, "GR": 27, "GT": 28, "HR": 21, "HU": 28, "IE": 22,
+	"IL": 23, "IQ": 23, "IS": 26, "IT": 27, "JO": 30, "KW": 30, "KZ": 20, "LB": 28, "LC": 32, "LI": 21, "LT": 20,
+	"LU": 20, "LV": 21, "LY": 25, "MC": 27, "MD": 24, "ME": 22, "MK": 19, "MR": 27, "MT": 31, "MU": 30, "NL": 18,
+	"NO": 15, "PK": 24, "PL": 28, "PS": 29, "PT": 25, "QA": 29, "RO": 24, "RS": 22, "RU": 33, "SA": 24, "SC": 31,
+	"SD": 18, "SE": 24, "SI": 19, "SK": 24, "SM": 27, "ST": 25, "SV": 28, "TL": 23, "TN": 24, "TR": 26, "UA": 29,
+	"VA": 22, "VG": 24, "XK": 20,
+}
+
+// IsQRIBAN reports whether a Swiss or Liechtenstein IBAN is a QR-IBAN, identified by an institution id from 30000 to 31999.
+func IsQRIBAN(iban string) bool {
+	iban = compactIBAN(iban)
+	if len(iban) < 9 {
+		return false
+	}
+	institution, err := strconv.Atoi(iban[4:9])
+	return err == nil && institution >= 30000 && institution <= 31999
+}
+
+// ValidateQRReference checks a 27 digit QR reference and its recursive modulo 10 check digit.
+func ValidateQRReference(reference string) error {
+	reference = strings.Replace(reference, " ", "", -1)
+	if len(reference) != 27 {
+		return ErrQRReference
+	}
+	carry := 0
+	for _, digit := range reference[:26] {
+		if digit < '0' || digit > '9' {
+			return ErrQRReference
+		}
+		carry = qrReferenceTable[(carry+int(digit-'0'))%10]
+	}
+	if int(reference[26]-'0') != (10-carry)%10 {
+		return ErrQRReference
+	}
+	return nil
+}
+
+// qrReferenceTable is the carry table of the recursive modulo 10 check digit.
+var qrReferenceTable = [10]int{0, 9, 4, 6, 8, 2, 7, 1, 3, 5}
+
+// ValidateCreditorReference checks an ISO 11649 RF creditor reference and its mod 97 checksum.
+func ValidateCreditorReference(reference string) error {
+	reference = strings.ToUpper(strings.Replace(reference, " ", "", -1))
+	if len(reference) < 5 || len(reference) > 25 || !strings.HasPrefix(reference, "RF") || !isAlphanumeric(reference) {
+		return ErrCreditorReference
+	}
+	if mod97(reference[4:]+reference[:4]) != 1 {
+		return ErrCreditorReference
+	}
+	return nil
+}
+
+// mod97 returns the remainder of value divided by 97 with letters counting as 10 to 35.
+func mod97(value string) int {
+	digits := ""
+	for _, character := range value {
+		if character >= 'A' && character <= 'Z' {
+			digits += strconv.Itoa(int(character-'A') + 10)
+		} else {
+			digits += string(character)
+		}
+	}
+	number, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return -1
+	}
+	return int(new(big.Int).Mod(number, big.NewInt(97)).Int64())
+}
+
+func isAlphanumeric(value string) bool {
+	for _, character := range value {
+		if (character < '0' || character > '9') && (character < 'A' || character > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// formatGroups splits value into groups of size from the left, or from the right when size is negative, joined by spaces.
+func formatGroups(value string, size int) string {
+	if size < 0 {
+		size = -size
+		head := len(value) % size
+		groups := []string{}
+		if head > 0 {
+			groups = append(groups, value[:head])
+		}
+		for index := head; index < len(value); index += size {
+			groups = append(groups, value[index:index+size])
+		}
+		return strings.Join(groups, " ")
+	}
+
+	groups := []string{}
+	for index := 0; index < len(value); index += size {
+		groups = append(groups, value[index:minInt(index+size, len(value))])
+	}
+	return strings.Join(groups, " ")
+}
+
+// compactIBAN removes the spaces an IBAN is printed with.
+func compactIBAN(iban string) string {
+	return strings.ToUpper(strings.Replace(strings.TrimSpace(iban), " ", "", -1))
+}
+
+// GetQRAddress reads a payment address from the "name", "street", "building", "postcode", "town" and "country" properties
+// of logic, interpolated by Interpolate.
+func (p *JSONGOFPDF) GetQRAddress(logic string) QRAddress {
+	return QRAddress{
+		Name:           p.Interpolate(p.GetString("name", logic, "")),
+		Street:         p.Interpolate(p.GetString("street", logic, "")),
+		BuildingNumber: p.Interpolate(p.GetString("building", logic, "")),
+		PostCode:       p.Interpolate(p.GetString("postcode", logic, "")),
+		Town:           p.Interpolate(p.GetString("town", logic, "")),
+		Country:        p.Interpolate(p.GetString("country", logic, "")),
+	}
+}
+
+// GetEPCPayment reads an EPC payment from the "bic", "name", "iban", "amount", "purpose", "reference", "remittance" and
+// "information" properties of logic, each interpolated by Interpolate.
+func (p *JSONGOFPDF) GetEPCPayment(logic string) EPCPayment {
+	return EPCPayment{
+		BIC:         p.Interpolate(p.GetString("bic", logic, "")),
+		Name:        p.Interpolate(p.GetString("name", logic, "")),
+		IBAN:        p.Interpolate(p.GetString("iban", logic, "")),
+		Amount:      cast.ToFloat64(p.Interpolate(p.GetString("amount", logic, ""))),
+		Purpose:     p.Interpolate(p.GetString("purpose", logic, "")),
+		Reference:   p.Interpolate(p.GetString("reference", logic, "")),
+		Remittance:  p.Interpolate(p.GetString("remittance", logic, "")),
+		Information: p.Interpolate(p.GetString("information", logic, "")),
+	}
+}
+
+// GetSwissQRBill reads a Swiss QR-bill from the "iban", "amount", "currency", "referencetype", "reference", "message" and
+// "billinformation" properties of logic, the creditor address from "creditor" and the debtor address from "debtor"
+// objects read by GetQRAddress. Values are interpolated by Interpolate.
+func (p *JSONGOFPDF) GetSwissQRBill(logic string) SwissQRBill {
+	bill := SwissQRBill{
+		IBAN:            p.Interpolate(p.GetString("iban", logic, "")),
+		Amount:          cast.ToFloat64(p.Interpolate(p.GetString("amount", logic, ""))),
+		Currency:        p.Interpolate(p.GetString("currency", logic, "CHF")),
+		ReferenceType:   p.Interpolate(p.GetString("referencetype", logic, "")),
+		Reference:       p.Interpolate(p.GetString("reference", logic, "")),
+		Message:         p.Interpolate(p.GetString("message", logic, "")),
+		BillInformation: p.Interpolate(p.GetString("billinformation", logic, "")),
+	}
+	if creditor := p.GetString("creditor", logic, ""); creditor != "" {
+		bill.Creditor = p.GetQRAddress(creditor)
+	}
+	if debtor := p.GetString("debtor", logic, ""); debtor != "" {
+		bill.Debtor = p.GetQRAddress(debtor)
+	}
+	return bill
+}
+
+// DrawSwissCross draws the Swiss cross over the centre of a Swiss QR code of size at x, y, excluding the quiet zone. The
+// cross is 7mm on a 46mm code.
+func DrawSwissCross(pdf *gofpdf.Fpdf, x float64, y float64, size float64) {
+	fillR, fillG, fillB := pdf.GetFillColor()
+	unit := size / 46
+
+	// White border, black square and a white cross of the flag proportions
+	crossX, crossY := x+size/2-3.5*unit, y+size/2-3.5*unit
+	pdf.SetFillColor(255, 255, 255)
+	pdf.Rect(crossX, crossY, 7*unit, 7*unit, "F")
+	pdf.SetFillColor(0, 0, 0)
+	pdf.Rect(crossX+0.5*unit, crossY+0.5*unit, 6*unit, 6*unit, "F")
+	arm, span := 6*unit*6/32, 6*unit*20/32
+	pdf.SetFillColor(255, 255, 255)
+	pdf.Rect(x+size/2-arm/2, y+size/2-span/2, arm, span, "F")
+	pdf.Rect(x+size/2-span/2, y+size/2-arm/2, span, arm, "F")
+
+	pdf.SetFillColor(fillR, fillG, fillB)
+}
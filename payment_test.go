@@ -0,0 +1,143 @@
+package jsongofpdf
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEPCPaymentPayload(t *testing.T) {
+	payment := EPCPayment{
+		BIC:        "BHBLDEHHXXX",
+		Name:       "Franz Mustermänn",
+		IBAN:       "DE71 1102 2033 0123 4567 89",
+		Amount:     12.3,
+		Remittance: "Invoice 42",
+	}
+
+	expected := "BCD\n002\n1\nSCT\nBHBLDEHHXXX\nFranz Mustermänn\nDE71110220330123456789\nEUR12.30\n\n\nInvoice 42"
+	if result := payment.Payload(); result != expected {
+		t.Fatalf("Payload should be %q, got %q", expected, result)
+	}
+}
+
+func TestSwissQRBillPayload(t *testing.T) {
+	bill := SwissQRBill{
+		IBAN: "CH44 3199 9123 0008 8901 2",
+		Creditor: QRAddress{
+			Name:           "Robert Schneider AG",
+			Street:         "Rue du Lac",
+			BuildingNumber: "1268",
+			PostCode:       "2501",
+			Town:           "Biel",
+			Country:        "CH",
+		},
+		Amount:    1949.75,
+		Reference: "21 00000 00003 13947 14300 09017",
+		Message:   "Order of 15 June 2020",
+	}
+
+	lines := strings.Split(bill.Payload(), "\r\n")
+	if len(lines) != 31 {
+		t.Fatalf("Payload should have 31 lines, got %d", len(lines))
+	}
+	expected := map[int]string{
+		0:  "SPC",
+		3:  "CH4431999123000889012",
+		4:  "S",
+		5:  "Robert Schneider AG",
+		18: "1949.75",
+		19: "CHF",
+		20: "",
+		27: "QRR",
+		28: "210000000003139471430009017",
+		29: "Order of 15 June 2020",
+		30: "EPD",
+	}
+	for index, value := range expected {
+		if lines[index] != value {
+			t.Fatalf("Payload line %d should be %q, got %q", index, value, lines[index])
+		}
+	}
+}
+
+func TestValidatePaymentReferences(t *testing.T) {
+	tests := []struct {
+		name     string
+		validate func(string) error
+		value    string
+		expected error
+	}{
+		{"IBAN", ValidateIBAN, "CH93 0076 2011 6238 5295 7", nil},
+		{"IBAN lowercase", ValidateIBAN, "de89370400440532013000", nil},
+		{"IBAN checksum", ValidateIBAN, "CH93 0076 2011 6238 5295 8", ErrIBAN},
+		{"IBAN characters", ValidateIBAN, "CH93-0076-2011-6238-5295-7", ErrIBAN},
+		{"IBAN country length", ValidateIBAN, "DE54 3704 0044 0532 0130 001", ErrIBAN},
+		{"QR reference", ValidateQRReference, "21 00000 00003 13947 14300 09017", nil},
+		{"QR reference check digit", ValidateQRReference, "210000000003139471430009018", ErrQRReference},
+		{"QR reference length", ValidateQRReference, "2100000000031394714300090", ErrQRReference},
+		{"Creditor reference", ValidateCreditorReference, "RF18 5390 0754 7034", nil},
+		{"Creditor reference checksum", ValidateCreditorReference, "RF19 5390 0754 7034", ErrCreditorReference},
+	}
+
+	for _, test := range tests {
+		if err := test.validate(test.value); err != test.expected {
+			t.Fatalf("%s %q should return %v, got %v", test.name, test.value, test.expected, err)
+		}
+	}
+}
+
+func TestSwissQRBillValidate(t *testing.T) {
+	creditor := QRAddress{Name: "Robert Schneider AG", PostCode: "2501", Town: "Biel", Country: "CH"}
+	tests := []struct {
+		name     string
+		bill     SwissQRBill
+		expected error
+	}{
+		{"QR reference", SwissQRBill{IBAN: "CH44 3199 9123 0008 8901 2", Creditor: creditor, Reference: "210000000003139471430009017"}, nil},
+		{"QR-IBAN without reference", SwissQRBill{IBAN: "CH44 3199 9123 0008 8901 2", Creditor: creditor}, ErrReferenceType},
+		{"Creditor reference", SwissQRBill{IBAN: "CH93 0076 2011 6238 5295 7", Creditor: creditor, Reference: "RF18539007547034"}, nil},
+		{"IBAN with QR reference", SwissQRBill{IBAN: "CH93 0076 2011 6238 5295 7", Creditor: creditor, Reference: "210000000003139471430009017"}, ErrReferenceType},
+		{"German IBAN", SwissQRBill{IBAN: "DE89 3704 0044 0532 0130 00", Creditor: creditor}, ErrIBANCountry},
+		{"Missing town", SwissQRBill{IBAN: "CH93 0076 2011 6238 5295 7", Creditor: QRAddress{Name: "A", Country: "CH"}}, ErrPaymentAddress},
+		{"Currency", SwissQRBill{IBAN: "CH93 0076 2011 6238 5295 7", Creditor: creditor, Currency: "USD"}, ErrPaymentCurrency},
+	}
+
+	for _, test := range tests {
+		if err := test.bill.Validate(); err != test.expected {
+			t.Fatalf("%s should return %v, got %v", test.name, test.expected, err)
+		}
+	}
+}
+
+func TestEPCPaymentValidate(t *testing.T) {
+	payment := EPCPayment{Name: "Franz Mustermann", IBAN: "DE89 3704 0044 0532 0130 00", Amount: 12.3}
+	withReference := payment
+	withReference.Reference = "RF18 5390 0754 7034"
+	both := withReference
+	both.Remittance = "Invoice 42"
+	invalid := EPCPayment{IBAN: "DE89 3704 0044 0532 0130 01", Purpose: "GOODS", Reference: "RF19539007547034", Information: strings.Repeat("x", 71)}
+	long := payment
+	long.Remittance = strings.Repeat("x", 140)
+	long.Information = strings.Repeat("x", 70)
+	long.BIC = "COBADEFFXXX"
+	long.Purpose = "GDDS"
+	long.Name = strings.Repeat("x", 70)
+	tests := []struct {
+		name     string
+		payment  EPCPayment
+		expected error
+	}{
+		{"Remittance", payment, nil},
+		{"Reference", withReference, nil},
+		{"Reference and remittance", both, PaymentErrors{ErrPaymentReference}},
+		{"Every field", invalid, PaymentErrors{ErrIBAN, ErrPaymentName, ErrPaymentPurpose, ErrCreditorReference, ErrPaymentNote}},
+		{"Payload", long, PaymentErrors{ErrPaymentPayload}},
+	}
+
+	for _, test := range tests {
+		if err := test.payment.Validate(); !reflect.DeepEqual(err, test.expected) {
+			t.Fatalf("%s should return %v, got %v", test.name, test.expected, err)
+		}
+	}
+}
@@ -0,0 +1,388 @@
+package jsongofpdf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/boombuler/barcode/qr"
+	"github.com/buger/jsonparser"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// paymentSlipLabels are the headings of the payment slips by language.
+var paymentSlipLabels = map[string]map[string]string{
+	"en": {
+		"receipt":     "Receipt",
+		"payment":     "Payment part",
+		"account":     "Account / Payable to",
+		"reference":   "Reference",
+		"information": "Additional information",
+		"payableby":   "Payable by",
+		"payablename": "Payable by (name/address)",
+		"currency":    "Currency",
+		"amount":      "Amount",
+		"acceptance":  "Acceptance point",
+		"scan":        "Scan to pay",
+		"beneficiary": "Beneficiary",
+		"remittance":  "Remittance information",
+		"iban":        "IBAN",
+		"bic":         "BIC",
+	},
+	"de": {
+		"receipt":     "Empfangsschein",
+		"payment":     "Zahlteil",
+		"account":     "Konto / Zahlbar an",
+		"reference":   "Referenz",
+		"information": "Zusätzliche Informationen",
+		"payableby":   "Zahlbar durch",
+		"payablename": "Zahlbar durch (Name/Adresse)",
+		"currency":    "Währung",
+		"amount":      "Betrag",
+		"acceptance":  "Annahmestelle",
+		"scan":        "Zahlen mit Code",
+		"beneficiary": "Empfänger",
+		"remittance":  "Verwendungszweck",
+		"iban":        "IBAN",
+		"bic":         "BIC",
+	},
+	"fr": {
+		"receipt":     "Récépissé",
+		"payment":     "Section paiement",
+		"account":     "Compte / Payable à",
+		"reference":   "Référence",
+		"information": "Informations supplémentaires",
+		"payableby":   "Payable par",
+		"payablename": "Payable par (nom/adresse)",
+		"currency":    "Monnaie",
+		"amount":      "Montant",
+		"acceptance":  "Point de dépôt",
+		"scan":        "Scanner pour payer",
+		"beneficiary": "Bénéficiaire",
+		"remittance":  "Communication",
+		"iban":        "IBAN",
+		"bic":         "BIC",
+	},
+	"it": {
+		"receipt":     "Ricevuta",
+		"payment":     "Sezione pagamento",
+		"account":     "Conto / Pagabile a",
+		"reference":   "Riferimento",
+		"information": "Informazioni supplementari",
+		"payableby":   "Pagabile da",
+		"payablename": "Pagabile da (nome/indirizzo)",
+		"currency":    "Valuta",
+		"amount":      "Importo",
+		"acceptance":  "Punto di accettazione",
+		"scan":        "Inquadra per pagare",
+		"beneficiary": "Beneficiario",
+		"remittance":  "Causale",
+		"iban":        "IBAN",
+		"bic":         "BIC",
+	},
+}
+
+// paymentSlip draws a payment slip in millimetres from its top left corner at x, y in document units, whatever the unit of
+// the document, in the Helvetica typography the payment standards ask for.
+type paymentSlip struct {
+	p      *JSONGOFPDF
+	pdf    *gofpdf.Fpdf
+	x      float64
+	y      float64
+	mm     float64
+	labels map[string]string
+}
+
+func (p *JSONGOFPDF) newPaymentSlip(pdf *gofpdf.Fpdf, x float64, y float64, language string) *paymentSlip {
+	labels, ok := paymentSlipLabels[strings.ToLower(language)]
+	if !ok {
+		labels = paymentSlipLabels["en"]
+	}
+	return &paymentSlip{p: p, pdf: pdf, x: x, y: y, mm: 72 / 25.4 / pdf.GetConversionRatio(), labels: labels}
+}
+
+// text writes lines of text in size points at left, top millimetres within width, each line height millimetres high,
+// returning the top of the next line.
+func (s *paymentSlip) text(left float64, top float64, width float64, height float64, size float64, bold bool, align string, lines ...string) float64 {
+	style := ""
+	if bold {
+		style = "B"
+	}
+	s.pdf.SetFont("Helvetica", style, size)
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		for _, part := range s.p.SplitText(s.pdf, s.p.tr(line), width*s.mm, &TextStyle{}) {
+			s.pdf.SetXY(s.x+left*s.mm, s.y+top*s.mm)
+			s.pdf.CellFormat(width*s.mm, height*s.mm, part, "", 0, align, false, 0, "")
+			top += height
+		}
+	}
+	return top
+}
+
+// corners draws the corner marks of a blank field for the payer to fill in.
+func (s *paymentSlip) corners(left float64, top float64, width float64, height float64) {
+	s.pdf.SetLineWidth(0.75 / s.pdf.GetConversionRatio())
+	s.pdf.SetDrawColor(0, 0, 0)
+	mark := 3.0
+	for _, corner := range [][4]float64{{left, top, 1, 1}, {left + width, top, -1, 1}, {left, top + height, 1, -1}, {left + width, top + height, -1, -1}} {
+		cornerX, cornerY := s.x+corner[0]*s.mm, s.y+corner[1]*s.mm
+		s.pdf.Line(cornerX, cornerY, cornerX+corner[2]*mark*s.mm, cornerY)
+		s.pdf.Line(cornerX, cornerY, cornerX, cornerY+corner[3]*mark*s.mm)
+	}
+}
+
+// separator draws a dotted cutting line from left, top to right, bottom millimetres with a scissors symbol at its start.
+func (s *paymentSlip) separator(left float64, top float64, right float64, bottom float64, scissors bool) {
+	s.pdf.SetLineWidth(0.2 * s.mm)
+	s.pdf.SetDrawColor(0, 0, 0)
+	s.pdf.SetDashPattern([]float64{0.6 * s.mm, 0.6 * s.mm}, 0)
+	s.pdf.Line(s.x+left*s.mm, s.y+top*s.mm, s.x+right*s.mm, s.y+bottom*s.mm)
+	s.pdf.SetDashPattern([]float64{}, 0)
+	if !scissors {
+		return
+	}
+
+	// The ZapfDingbats scissors point right, turned to point down the vertical line
+	s.pdf.SetFont("ZapfDingbats", "", 10)
+	width := s.pdf.GetStringWidth("\x22")
+	_, height := s.pdf.GetFontSize()
+	symbolX, symbolY := s.x+(left+5)*s.mm, s.y+top*s.mm
+	if left == right {
+		symbolX, symbolY = s.x+left*s.mm, s.y+(top+5)*s.mm
+		s.pdf.TransformBegin()
+		s.pdf.TransformRotate(-90, symbolX, symbolY)
+	}
+	s.pdf.SetFillColor(255, 255, 255)
+	s.pdf.Rect(symbolX, symbolY-height/2, width, height, "F")
+	s.pdf.Text(symbolX, symbolY+height*0.35, "\x22")
+	if left == right {
+		s.pdf.TransformEnd()
+	}
+}
+
+// reset switches to black text without cell margins or page breaks, as the slip is laid out to the foot of the page.
+func (s *paymentSlip) reset() {
+	_, margin := s.pdf.GetAutoPageBreak()
+	s.pdf.SetTextColor(0, 0, 0)
+	s.pdf.SetCellMargin(0)
+	s.pdf.SetAutoPageBreak(false, margin)
+}
+
+// qr draws the payload as a QR code of error correction level M size millimetres wide at left, top.
+func (s *paymentSlip) qr(payload string, left float64, top float64, size float64) (codeSize float64, err error) {
+	code, err := qr.Encode(payload, qr.M, qr.Auto)
+	if err != nil {
+		return 0, err
+	}
+	module := size * s.mm / float64(code.Bounds().Dx())
+	s.pdf.SetFillColor(0, 0, 0)
+	DrawMatrixCode(s.pdf, code, s.x+left*s.mm, s.y+top*s.mm, module, module)
+	return size * s.mm, nil
+}
+
+// addressLines are the lines an address is printed in.
+func addressLines(address QRAddress) []string {
+	street := strings.TrimSpace(address.Street + " " + address.BuildingNumber)
+	town := strings.TrimSpace(address.PostCode + " " + address.Town)
+	if address.Country != "" && !strings.EqualFold(address.Country, "CH") && !strings.EqualFold(address.Country, "LI") {
+		town = strings.ToUpper(address.Country) + "-" + town
+	}
+	return []string{address.Name, street, town}
+}
+
+// formatAmount formats an amount with spaces between thousands as the payment slips print it.
+func formatAmount(amount float64) string {
+	value := strconv.FormatFloat(amount, 'f', 2, 64)
+	return formatGroups(value[:len(value)-3], -3) + value[len(value)-3:]
+}
+
+// formatReference formats a QR reference in groups of five from the right and creditor references in groups of four.
+func formatReference(referenceType string, reference string) string {
+	reference = strings.Replace(reference, " ", "", -1)
+	if referenceType == "QRR" {
+		return formatGroups(reference, -5)
+	}
+	return formatGroups(reference, 4)
+}
+
+// getPaymentLogic returns the payment properties from the object at the "data" path in the bound Data when one is given,
+// otherwise the properties of logic.
+func (p *JSONGOFPDF) getPaymentLogic(logic string) string {
+	dataPath := p.GetString("data", logic, "")
+	if dataPath == "" {
+		return logic
+	}
+	value, dataType, _, err := jsonparser.Get([]byte(p.Data), strings.Split(dataPath, ".")...)
+	if err != nil || dataType != jsonparser.Object {
+		return logic
+	}
+	return string(value)
+}
+
+// SwissQRBill maps json to the 210mm by 105mm Swiss QR-bill with its receipt and payment part. The bill is read by
+// GetSwissQRBill from the properties of logic, or of the object at the "data" path in the bound Data, and validated by
+// SwissQRBill.Validate, printing the error and drawing nothing when it is invalid. Pass "x", "y" float for the top left
+// corner, with a y of -1 placing the bill at the foot of the page, "language" string as "en", "de", "fr" or "it" and
+// "scissors" bool to mark the cutting lines.
+// Defaults are "data": "", "x": 0.0, "y": -1.0, "language": "en", "scissors": true
+func (p *JSONGOFPDF) SwissQRBill(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
+	bill := p.GetSwissQRBill(p.getPaymentLogic(logic))
+	if err := bill.Validate(); err != nil {
+		fmt.Println(err)
+		return pdf
+	}
+
+	slip := p.newPaymentSlip(pdf, p.GetFloat("x", logic, 0.0), p.GetFloat("y", logic, -1.0), p.GetString("language", logic, "en"))
+	if slip.y < 0 {
+		_, pageHeight := pdf.GetPageSize()
+		slip.y = pageHeight - 105*slip.mm
+	}
+	defer p.saveGraphics(pdf)()
+	slip.reset()
+
+	labels := slip.labels
+	referenceType := bill.referenceType()
+	iban := formatGroups(compactIBAN(bill.IBAN), 4)
+	reference := ""
+	if referenceType != "NON" {
+		reference = formatReference(referenceType, bill.Reference)
+	}
+	currency := strings.ToUpper(bill.Currency)
+	if currency == "" {
+		currency = "CHF"
+	}
+	amount := ""
+	if bill.Amount > 0 {
+		amount = formatAmount(bill.Amount)
+	}
+
+	scissors := p.GetBool("scissors", logic, true)
+	slip.separator(0, 0, 210, 0, scissors)
+	slip.separator(62, 0, 62, 105, scissors)
+
+	// Receipt, 62mm wide with headings of 6pt and values of 8pt
+	slip.text(5, 5, 52, 5, 11, true, "L", labels["receipt"])
+	top := slip.text(5, 12, 52, 3, 6, true, "L", labels["account"])
+	top = slip.text(5, top, 52, 3.2, 8, false, "L", append([]string{iban}, addressLines(bill.Creditor)...)...)
+	if reference != "" {
+		top = slip.text(5, top+2.5, 52, 3, 6, true, "L", labels["reference"])
+		top = slip.text(5, top, 52, 3.2, 8, false, "L", reference)
+	}
+	if !bill.Debtor.IsEmpty() {
+		top = slip.text(5, top+2.5, 52, 3, 6, true, "L", labels["payableby"])
+		slip.text(5, top, 52, 3.2, 8, false, "L", addressLines(bill.Debtor)...)
+	} else {
+		top = slip.text(5, top+2.5, 52, 3, 6, true, "L", labels["payablename"])
+		slip.corners(5, top+1, 52, 20)
+	}
+	slip.text(5, 68, 12, 3, 6, true, "L", labels["currency"])
+	slip.text(5, 71.5, 12, 3.2, 8, false, "L", currency)
+	slip.text(18, 68, 39, 3, 6, true, "L", labels["amount"])
+	if amount != "" {
+		slip.text(18, 71.5, 39, 3.2, 8, false, "L", amount)
+	} else {
+		slip.corners(27, 70, 30, 10)
+	}
+	slip.text(5, 82, 52, 3, 6, true, "R", labels["acceptance"])
+
+	// Payment part, the QR code with the amount below it and the information to its right in 8pt headings and 10pt values
+	slip.text(67, 5, 51, 5, 11, true, "L", labels["payment"])
+	codeSize, err := slip.qr(bill.Payload(), 67, 17, 46)
+	if err != nil {
+		fmt.Println(err)
+		return pdf
+	}
+	DrawSwissCross(pdf, slip.x+67*slip.mm, slip.y+17*slip.mm, codeSize)
+	slip.text(67, 68, 20, 3.5, 8, true, "L", labels["currency"])
+	slip.text(67, 72, 20, 4, 10, false, "L", currency)
+	slip.text(89, 68, 29, 3.5, 8, true, "L", labels["amount"])
+	if amount != "" {
+		slip.text(89, 72, 29, 4, 10, false, "L", amount)
+	} else {
+		slip.corners(78, 72, 40, 15)
+	}
+
+	top = slip.text(118, 5, 87, 3.5, 8, true, "L", labels["account"])
+	top = slip.text(118, top, 87, 4, 10, false, "L", append([]string{iban}, addressLines(bill.Creditor)...)...)
+	if reference != "" {
+		top = slip.text(118, top+3, 87, 3.5, 8, true, "L", labels["reference"])
+		top = slip.text(118, top, 87, 4, 10, false, "L", reference)
+	}
+	if bill.Message != "" || bill.BillInformation != "" {
+		top = slip.text(118, top+3, 87, 3.5, 8, true, "L", labels["information"])
+		top = slip.text(118, top, 87, 4, 10, false, "L", bill.Message, bill.BillInformation)
+	}
+	if !bill.Debtor.IsEmpty() {
+		top = slip.text(118, top+3, 87, 3.5, 8, true, "L", labels["payableby"])
+		slip.text(118, top, 87, 4, 10, false, "L", addressLines(bill.Debtor)...)
+	} else {
+		top = slip.text(118, top+3, 87, 3.5, 8, true, "L", labels["payablename"])
+		slip.corners(118, top+1, 65, 25)
+	}
+
+	return pdf
+}
+
+// EPCSlip maps json to a SEPA credit transfer slip with an EPC QR code, also known as GiroCode, beside the payment details.
+// The payment is read by GetEPCPayment from the properties of logic, or of the object at the "data" path in the bound
+// Data, and validated by EPCPayment.Validate, printing the error and drawing nothing when it is invalid. Pass "x", "y"
+// float for the top left corner, "width" float in document units, "size" float for the QR code in millimetres, "language"
+// string as "en", "de", "fr" or "it" and "scissors" bool to draw a cutting line above the slip.
+// Defaults are "data": "", "x": 0.0, "y": 0.0, "width": 0.0 for the page width, "size": 30.0, "language": "en", "scissors": false
+func (p *JSONGOFPDF) EPCSlip(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
+	payment := p.GetEPCPayment(p.getPaymentLogic(logic))
+	if err := payment.Validate(); err != nil {
+		fmt.Println(err)
+		return pdf
+	}
+
+	slip := p.newPaymentSlip(pdf, p.GetFloat("x", logic, 0.0), p.GetFloat("y", logic, 0.0), p.GetString("language", logic, "en"))
+	defer p.saveGraphics(pdf)()
+	slip.reset()
+
+	width := p.GetFloat("width", logic, 0.0)
+	if width <= 0 {
+		pageWidth, _ := pdf.GetPageSize()
+		width = pageWidth - slip.x
+	}
+	width = width / slip.mm
+	size := p.GetFloat("size", logic, 30.0)
+	labels := slip.labels
+
+	if p.GetBool("scissors", logic, false) {
+		slip.separator(0, 0, width, 0, true)
+	}
+
+	slip.text(5, 5, size, 4, 8, true, "C", labels["scan"])
+	if _, err := slip.qr(payment.Payload(), 5, 10, size); err != nil {
+		fmt.Println(err)
+		return pdf
+	}
+
+	left := 5 + size + 8
+	column := width - left - 5
+	top := slip.text(left, 5, column, 3.5, 8, true, "L", labels["beneficiary"])
+	top = slip.text(left, top, column, 4, 10, false, "L", payment.Name)
+	top = slip.text(left, top+1.5, column, 3.5, 8, true, "L", labels["iban"])
+	top = slip.text(left, top, column, 4, 10, false, "L", formatGroups(compactIBAN(payment.IBAN), 4))
+	if payment.BIC != "" {
+		top = slip.text(left, top+1.5, column, 3.5, 8, true, "L", labels["bic"])
+		top = slip.text(left, top, column, 4, 10, false, "L", strings.ToUpper(payment.BIC))
+	}
+	if payment.Amount > 0 {
+		top = slip.text(left, top+1.5, column, 3.5, 8, true, "L", labels["amount"])
+		top = slip.text(left, top, column, 4, 10, false, "L", "EUR "+formatAmount(payment.Amount))
+	}
+	if payment.Reference != "" {
+		top = slip.text(left, top+1.5, column, 3.5, 8, true, "L", labels["reference"])
+		slip.text(left, top, column, 4, 10, false, "L", formatReference("SCOR", payment.Reference))
+	} else if payment.Remittance != "" {
+		top = slip.text(left, top+1.5, column, 3.5, 8, true, "L", labels["remittance"])
+		slip.text(left, top, column, 4, 10, false, "L", payment.Remittance)
+	}
+
+	return pdf
+}
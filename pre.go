@@ -1,6 +1,7 @@
 package jsongofpdf
 
 import (
+	"math"
 	"strings"
 
 	"github.com/buger/jsonparser"
@@ -10,6 +11,8 @@ import (
 
 // PreOperations will iterate through the array of operations and execute each
 func (p *JSONGOFPDF) PreOperations(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
+	// Operations are measured at the x they render at, which setx operations move
+	x := pdf.GetX()
 	jsonparser.ArrayEach([]byte(logic), func(value []byte, dataType jsonparser.ValueType, offset int, err error) {
 		switch dataType {
 		case jsonparser.Object:
@@ -17,6 +20,7 @@ func (p *JSONGOFPDF) PreOperations(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf
 			break
 		}
 	})
+	pdf.SetX(x)
 
 	return pdf
 }
@@ -34,9 +38,24 @@ func (p *JSONGOFPDF) PreParseObject(pdf *gofpdf.Fpdf, logic string) (opdf *gofpd
 // PreRunOperation determines which function to run for the pre-render pipeline
 func (p *JSONGOFPDF) PreRunOperation(pdf *gofpdf.Fpdf, name string, logic string) (opdf *gofpdf.Fpdf) {
 	switch name {
+	case "setx":
+		p.SetX(pdf, logic)
+		break
 	case "multicell":
 		p.PreRowMultiCell(pdf, logic)
 		break
+	case "html":
+		p.PreRowHTML(pdf, logic, false)
+		break
+	case "markdown":
+		p.PreRowHTML(pdf, logic, true)
+		break
+	case "sparkline":
+		p.PreRowSparkline(pdf, logic)
+		break
+	case "tablecell":
+		p.PreRowTableCell(pdf, logic)
+		break
 	}
 	return pdf
 }
@@ -49,7 +68,52 @@ func (p *JSONGOFPDF) PreRowMultiCell(pdf *gofpdf.Fpdf, logic string) (opdf *gofp
 	height := p.GetFloat("height", logic, 0.0) // Line height of each cell, not cell height
 	text := p.GetString("text", logic, "")
 
-	cell := p.Tables[p.TableIndex].Rows[p.RowIndex].Cells[p.CellPreIndex]
+	cell := p.PreCell(target, text)
+
+	if cell.Disabled == false {
+		renderText := ""
+		switch attribute {
+		case "title":
+			renderText = p.tr(strings.Replace(cell.Title, "<br>", "\n", -1))
+			break
+		case "value":
+			renderText = p.tr(strings.Replace(cast.ToString(cell.Value), "<br>", "\n", -1))
+		}
+
+		textStyle := p.GetTextStyle(logic)
+		p.ApplyTextStyle(pdf, textStyle)
+		fontSize, _ := pdf.GetFontSize()
+		renderText, lineHeight := p.FitText(pdf, logic, renderText, width, height, true, textStyle)
+		cellList := p.SplitText(pdf, renderText, width, textStyle)
+		pdf.SetFontSize(fontSize)
+		p.RestoreTextStyle(pdf, textStyle)
+
+		cellHeight := float64(len(cellList)) * lineHeight
+		if gallery := p.GetGallery(pdf, logic); gallery != nil && cell.Images != nil && attribute == "value" {
+			cellHeight += p.GalleryHeight(pdf, cell.Images, width, gallery)
+		}
+		cellCount := math.Ceil(cellHeight/height - 0.000001)
+
+		if cellCount > p.RowCells {
+			p.RowCells = cellCount
+		}
+
+		if cellHeight > p.RowHeight {
+			p.RowHeight = cellHeight
+		}
+	}
+
+	return pdf
+}
+
+// PreCell resolves the cell a pre-render operation measures. This is the row cell being measured, replaced by text when
+// given, and disabled when target names another cell unless a global matches target.
+func (p *JSONGOFPDF) PreCell(target string, text string) (cell Cell) {
+	if len(p.Tables) > p.TableIndex && len(p.Tables[p.TableIndex].Rows) > p.RowIndex {
+		if cells := p.Tables[p.TableIndex].Rows[p.RowIndex].Cells; len(cells) > p.CellPreIndex {
+			cell = cells[p.CellPreIndex]
+		}
+	}
 
 	if text != "" {
 		cell = Cell{
@@ -73,34 +137,40 @@ func (p *JSONGOFPDF) PreRowMultiCell(pdf *gofpdf.Fpdf, logic string) (opdf *gofp
 		}
 	}
 
-	if cell.Disabled == false {
-		renderText := ""
-		switch attribute {
-		case "title":
-			renderText = p.tr(strings.Replace(cell.Title, "<br>", "\n", -1))
-			break
-		case "value":
-			renderText = p.tr(strings.Replace(cast.ToString(cell.Value), "<br>", "\n", -1))
-		}
-
-		cellList := pdf.SplitLines([]byte(renderText), width)
-		cellHeight := float64(len(cellList)) * height
-		cellCount := float64(len(cellList))
+	return cell
+}
 
-		if cellCount > p.RowCells {
-			p.RowCells = cellCount
-		}
+// PreRowHTML measures an html or markdown field with a dry run of WriteHTML before rendering it in the row so it counts
+// towards the row height
+func (p *JSONGOFPDF) PreRowHTML(pdf *gofpdf.Fpdf, logic string, markdown bool) (opdf *gofpdf.Fpdf) {
+	width := p.GetFloat("width", logic, 0.0)
+	height := p.GetFloat("height", logic, 5.0)
 
-		if cellHeight > p.RowHeight {
-			p.RowHeight = cellHeight
-		}
+	renderText, ok := p.markupText(logic, markdown, true)
+	if !ok {
+		return pdf
 	}
 
-	// This stops the first cell being left behind
-	_, pageHeight := pdf.GetPageSize()
-	if pdf.GetY()+p.RowHeight > pageHeight {
-		pdf.AddPage()
+	textStyle := p.GetTextStyle(logic)
+	p.ApplyTextStyle(pdf, textStyle)
+	cellHeight := p.MeasureHTML(pdf, pdf.GetX(), width, height, renderText)
+	p.RestoreTextStyle(pdf, textStyle)
+
+	cellCount := math.Ceil(cellHeight/height - 0.000001)
+	if cellCount > p.RowCells {
+		p.RowCells = cellCount
+	}
+	if cellHeight > p.RowHeight {
+		p.RowHeight = cellHeight
 	}
+	return pdf
+}
 
+// PreRowSparkline counts the height of a sparkline cell towards the row height before rendering the row
+func (p *JSONGOFPDF) PreRowSparkline(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
+	height := p.GetFloat("height", logic, 5.0)
+	if height > p.RowHeight {
+		p.RowHeight = height
+	}
 	return pdf
 }
@@ -0,0 +1,186 @@
+package jsongofpdf
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/datamatrix"
+	"github.com/boombuler/barcode/pdf417"
+	"github.com/boombuler/barcode/qr"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// qrModuleSize is the size of a module in millimetres when no code size is given.
+const qrModuleSize = 0.5
+
+// EncodeMatrixCode encodes value as a "qr", "datamatrix" or "pdf417" code. level is the QR error correction level "L",
+// "M", "Q" or "H" and the PDF417 security level "0" to "8".
+func EncodeMatrixCode(kind string, value string, level string) (code barcode.Barcode, err error) {
+	if value == "" {
+		return nil, ErrBarcodeValue
+	}
+
+	switch kind {
+	case "qr":
+		ecl := qr.M
+		switch strings.ToUpper(level) {
+		case "L":
+			ecl = qr.L
+			break
+		case "Q":
+			ecl = qr.Q
+			break
+		case "H":
+			ecl = qr.H
+			break
+		}
+		return qr.Encode(value, ecl, qr.Auto)
+	case "datamatrix":
+		return datamatrix.Encode(value)
+	case "pdf417":
+		security := 2
+		if level != "" {
+			if _, err = fmt.Sscanf(level, "%d", &security); err != nil {
+				return nil, err
+			}
+		}
+		return pdf417.Encode(value, byte(security))
+	}
+	return nil, ErrBarcodeType
+}
+
+// DrawMatrixCode draws the dark modules of code at x, y as filled vector rectangles of moduleWidth by moduleHeight, joining
+// modules along each row into a single rectangle and filling them all as one path so no seams show between them.
+func DrawMatrixCode(pdf *gofpdf.Fpdf, code barcode.Barcode, x float64, y float64, moduleWidth float64, moduleHeight float64) {
+	bounds := code.Bounds()
+	for row := bounds.Min.Y; row < bounds.Max.Y; row++ {
+		top := y + float64(row-bounds.Min.Y)*moduleHeight
+		start := -1
+		for column := bounds.Min.X; column <= bounds.Max.X; column++ {
+			dark := false
+			if column < bounds.Max.X {
+				luminance, _, _, _ := code.At(column, row).RGBA()
+				dark = luminance < 0x8000
+			}
+			if dark && start < 0 {
+				start = column
+			} else if !dark && start >= 0 {
+				left := x + float64(start-bounds.Min.X)*moduleWidth
+				right := x + float64(column-bounds.Min.X)*moduleWidth
+				pdf.MoveTo(left, top)
+				pdf.LineTo(right, top)
+				pdf.LineTo(right, top+moduleHeight)
+				pdf.LineTo(left, top+moduleHeight)
+				pdf.ClosePath()
+				start = -1
+			}
+		}
+	}
+	pdf.DrawPath("F")
+}
+
+// QRCode maps json to a QR code drawn as vector rectangles. Pass in the value as "text" string, "data" string as a dotted
+// path into the bound Data or "target" string as a table cell, with "text" interpolated by Interpolate, or "payload" string
+// as "epc" to encode the EPC SEPA payment read by GetEPCPayment or "swissqr" to encode the Swiss QR-bill read by
+// GetSwissQRBill with the Swiss cross over its centre, drawing nothing for an empty value or when the payment does not
+// validate. "level" string is the error correction level "L", "M", "Q" or "H", always "M" for payments. Pass "x", "y",
+// "size" float for the width of the code including the quiet zone, or "module" float for the size of each module when
+// "size" is 0, "quiet" int modules of quiet zone, "color" and "background" strings as hex, rgb() or colour names with an
+// empty background left transparent, and "flow" bool to place the code at the current y and move below it.
+// Defaults are "text": "", "data": "", "target": "", "payload": "", "level": "M", "x": 0.0, "y": 0.0, "size": 0.0,
+// "module": 0.5mm, "quiet": 4, "color": "#000000", "background": "", "flow": false
+func (p *JSONGOFPDF) QRCode(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
+	return p.matrixCode(pdf, logic, "qr", 4)
+}
+
+// DataMatrix maps json to a DataMatrix code drawn as vector rectangles. Properties are those of QRCode without "payload"
+// and "level". Defaults are as QRCode with "quiet": 1
+func (p *JSONGOFPDF) DataMatrix(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
+	return p.matrixCode(pdf, logic, "datamatrix", 1)
+}
+
+// PDF417 maps json to a PDF417 code drawn as vector rectangles. Properties are those of QRCode without "payload", with
+// "level" string as the security level "0" to "8" and "size" as the width of the code.
+// Defaults are as QRCode with "level": "2", "quiet": 2
+func (p *JSONGOFPDF) PDF417(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
+	return p.matrixCode(pdf, logic, "pdf417", 2)
+}
+
+func (p *JSONGOFPDF) matrixCode(pdf *gofpdf.Fpdf, logic string, kind string, defaultQuiet int) (opdf *gofpdf.Fpdf) {
+	payload := p.GetString("payload", logic, "")
+	level := p.GetString("level", logic, "")
+	x := p.GetFloat("x", logic, 0.0)
+	y := p.GetFloat("y", logic, 0.0)
+	size := p.GetFloat("size", logic, 0.0)
+	module := p.GetFloat("module", logic, 0.0)
+	quiet := p.GetInt("quiet", logic, defaultQuiet)
+	color := p.GetString("color", logic, "#000000")
+	background := p.GetString("background", logic, "")
+	flow := p.GetBool("flow", logic, false)
+
+	value := ""
+	if kind == "qr" && payload != "" {
+		// Payment codes must use error correction level M
+		level = "M"
+		switch payload {
+		case "epc":
+			payment := p.GetEPCPayment(logic)
+			if err := payment.Validate(); err != nil {
+				fmt.Println(err)
+				return pdf
+			}
+			value = payment.Payload()
+			break
+		case "swissqr":
+			bill := p.GetSwissQRBill(logic)
+			if err := bill.Validate(); err != nil {
+				fmt.Println(err)
+				return pdf
+			}
+			value = bill.Payload()
+			break
+		}
+	} else {
+		value = p.Interpolate(p.GetBarcodeValue(logic))
+	}
+	if value == "" {
+		return pdf
+	}
+
+	code, err := EncodeMatrixCode(kind, value, level)
+	if err != nil {
+		fmt.Println(err)
+		return pdf
+	}
+
+	bounds := code.Bounds()
+	columns, rows := float64(bounds.Dx()+2*quiet), float64(bounds.Dy()+2*quiet)
+	if size > 0 {
+		module = size / columns
+	} else if module <= 0 {
+		module = qrModuleSize * 72 / 25.4 / pdf.GetConversionRatio()
+	}
+	if flow {
+		y = pdf.GetY()
+	}
+
+	fillR, fillG, fillB := pdf.GetFillColor()
+	if r, g, b, ok := ParseColor(background); ok {
+		pdf.SetFillColor(r, g, b)
+		pdf.Rect(x, y, columns*module, rows*module, "F")
+	}
+	r, g, b, _ := ParseColor(color)
+	pdf.SetFillColor(r, g, b)
+	codeX, codeY := x+float64(quiet)*module, y+float64(quiet)*module
+	DrawMatrixCode(pdf, code, codeX, codeY, module, module)
+	if payload == "swissqr" {
+		DrawSwissCross(pdf, codeX, codeY, float64(bounds.Dx())*module)
+	}
+	pdf.SetFillColor(fillR, fillG, fillB)
+
+	if flow {
+		pdf.SetY(y + rows*module)
+	}
+	return pdf
+}
@@ -0,0 +1,148 @@
+package jsongofpdf
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	jsonlogic "github.com/GeorgeD19/json-logic-go"
+	"github.com/buger/jsonparser"
+	"github.com/spf13/cast"
+)
+
+// TableSort is a key table rows are ordered by, evaluated as json-logic over their Data.
+type TableSort struct {
+	Key        string
+	Descending bool
+	Type       string
+}
+
+// GetTableSorts reads the "sort" array of a tablefunc, most significant key first. Each sort takes a "key" as json-logic or
+// as a dotted path into the row data, "order" string as "asc" or "desc" and "type" string as "auto", "number", "string" or
+// "date". Defaults are "order": "asc", "type": "auto"
+func (p *JSONGOFPDF) GetTableSorts(logic string) (sorts []TableSort) {
+	jsonparser.ArrayEach([]byte(p.GetString("sort", logic, "")), func(value []byte, dataType jsonparser.ValueType, offset int, err error) {
+		switch dataType {
+		case jsonparser.Object:
+			key := p.GetString("key", string(value), "")
+			if key == "" {
+				break
+			}
+			sorts = append(sorts, TableSort{
+				Key:        logicKey(key),
+				Descending: strings.ToLower(p.GetString("order", string(value), "asc")) == "desc",
+				Type:       p.GetString("type", string(value), "auto"),
+			})
+			break
+		}
+	})
+	return sorts
+}
+
+// logicKey turns a dotted path into the row data into a json-logic var, passing json-logic through.
+func logicKey(key string) string {
+	if strings.HasPrefix(strings.TrimSpace(key), "{") {
+		return key
+	}
+	logic, _ := json.Marshal(map[string]string{"var": key})
+	return string(logic)
+}
+
+// SortTable returns a copy of the table with the rows, and their data, stably ordered by the sort keys.
+func SortTable(table Table, sorts []TableSort) (sorted Table) {
+	if len(sorts) == 0 {
+		return table
+	}
+
+	rowKeys := make([][]interface{}, len(table.Rows))
+	order := make([]int, len(table.Rows))
+	for index := range table.Rows {
+		order[index] = index
+		data := ""
+		if len(table.Data) > index {
+			data = table.Data[index]
+		}
+		for _, tableSort := range sorts {
+			key, _ := jsonlogic.Apply(tableSort.Key, data)
+			rowKeys[index] = append(rowKeys[index], key)
+		}
+	}
+
+	sort.SliceStable(order, func(a, b int) bool {
+		for level, tableSort := range sorts {
+			result := CompareTyped(rowKeys[order[a]][level], rowKeys[order[b]][level], tableSort.Type)
+			if tableSort.Descending {
+				result = -result
+			}
+			if result != 0 {
+				return result < 0
+			}
+		}
+		return false
+	})
+
+	return reorderTable(table, order)
+}
+
+// CompareTyped orders two values as "number", "string" or "date", or as CompareValues does for "auto", returning -1, 0
+// or 1. Values that are not valid dates are ordered before dates.
+func CompareTyped(a interface{}, b interface{}, valueType string) int {
+	switch valueType {
+	case "number":
+		return CompareValues(cast.ToFloat64(a), cast.ToFloat64(b))
+	case "string":
+		return strings.Compare(cast.ToString(a), cast.ToString(b))
+	case "date":
+		timeA, errA := cast.ToTimeE(a)
+		timeB, errB := cast.ToTimeE(b)
+		switch {
+		case errA != nil && errB != nil:
+			return 0
+		case errA != nil:
+			return -1
+		case errB != nil:
+			return 1
+		case timeA.Before(timeB):
+			return -1
+		case timeA.After(timeB):
+			return 1
+		}
+		return 0
+	}
+	return CompareValues(a, b)
+}
+
+// FilterTable returns a copy of the table with only the rows whose data the json-logic filter is truthy for.
+func FilterTable(table Table, filter string) (filtered Table) {
+	if filter == "" {
+		return table
+	}
+
+	order := make([]int, 0)
+	for index := range table.Rows {
+		data := ""
+		if len(table.Data) > index {
+			data = table.Data[index]
+		}
+		result, err := jsonlogic.Apply(filter, data)
+		if err == nil && truthy(result) {
+			order = append(order, index)
+		}
+	}
+	return reorderTable(table, order)
+}
+
+// truthy follows the json-logic rules of truth, where empty strings, arrays, zero and null are false.
+func truthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case []interface{}:
+		return len(v) > 0
+	}
+	return cast.ToFloat64(value) != 0
+}
@@ -0,0 +1,75 @@
+package jsongofpdf
+
+import (
+	"strings"
+	"testing"
+)
+
+func testTable(data ...string) (table Table) {
+	for _, row := range data {
+		table.Rows = append(table.Rows, Row{Cells: []Cell{{Key: "data", Value: row}}})
+		table.Data = append(table.Data, row)
+	}
+	return table
+}
+
+func tableIDs(table Table) string {
+	ids := make([]string, 0)
+	for index, data := range table.Data {
+		if table.Rows[index].Cells[0].Value != data {
+			return "misaligned"
+		}
+		ids = append(ids, data[7:8])
+	}
+	return strings.Join(ids, "")
+}
+
+func TestSortTable(t *testing.T) {
+	table := testTable(
+		`{"id":"a","amount":10,"name":"b","date":"2020-03-01"}`,
+		`{"id":"b","amount":9,"name":"a","date":"2019-12-31"}`,
+		`{"id":"c","amount":10,"name":"a","date":"2020-01-15"}`,
+	)
+	tests := []struct {
+		sorts []TableSort
+		ids   string
+	}{
+		{[]TableSort{{Key: `{"var":"amount"}`, Type: "number"}}, "bac"},
+		{[]TableSort{{Key: `{"var":"amount"}`, Descending: true}, {Key: `{"var":"name"}`, Type: "string"}}, "cab"},
+		{[]TableSort{{Key: `{"var":"date"}`, Type: "date", Descending: true}}, "acb"},
+		{nil, "abc"},
+	}
+	for _, test := range tests {
+		if ids := tableIDs(SortTable(table, test.sorts)); ids != test.ids {
+			t.Fatalf("SortTable(%v) = %s, want %s", test.sorts, ids, test.ids)
+		}
+	}
+}
+
+func TestFilterTable(t *testing.T) {
+	table := testTable(
+		`{"id":"a","active":true}`,
+		`{"id":"b","active":false}`,
+		`{"id":"c","active":1}`,
+	)
+	if ids := tableIDs(FilterTable(table, `{"var":"active"}`)); ids != "ac" {
+		t.Fatalf("FilterTable = %s, want ac", ids)
+	}
+}
+
+func TestLogicKey(t *testing.T) {
+	tests := []struct {
+		key   string
+		logic string
+	}{
+		{"amount", `{"var":"amount"}`},
+		{"customer.name", `{"var":"customer.name"}`},
+		{`say "hi"`, `{"var":"say \"hi\""}`},
+		{` {"var":"amount"}`, ` {"var":"amount"}`},
+	}
+	for _, test := range tests {
+		if logic := logicKey(test.key); logic != test.logic {
+			t.Fatalf("logicKey(%q) = %s, want %s", test.key, logic, test.logic)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package jsongofpdf
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+
+	"github.com/buger/jsonparser"
+	"github.com/jung-kurt/gofpdf"
+	"github.com/spf13/cast"
+)
+
+// GetSparklineValues returns the values of a sparkline, from the "data" path into the Data of the current table row when
+// given, otherwise from the value of the cell targeted by "target" and "loop" as for MultiCell. Values may be a json array
+// or a comma separated string.
+func (p *JSONGOFPDF) GetSparklineValues(logic string) (values []float64) {
+	var value interface{}
+	if dataPath := p.GetString("data", logic, ""); dataPath != "" {
+		if len(p.Tables) > p.TableIndex && len(p.Tables[p.TableIndex].Data) > p.RowIndex {
+			row := p.Tables[p.TableIndex].Data[p.RowIndex]
+			if attribute, _, _, err := jsonparser.Get([]byte(row), strings.Split(dataPath, ".")...); err == nil {
+				value = string(attribute)
+			}
+		}
+	} else {
+		value = p.GetCell(p.GetString("target", logic, ""), "", p.GetBool("loop", logic, false)).Value
+	}
+
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			values = append(values, cast.ToFloat64(item))
+		}
+		break
+	case []float64:
+		values = v
+		break
+	case string:
+		v = strings.TrimSpace(v)
+		if strings.HasPrefix(v, "[") {
+			json.Unmarshal([]byte(v), &values)
+			break
+		}
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, cast.ToFloat64(item))
+			}
+		}
+		break
+	}
+	return values
+}
+
+// Sparkline maps json to a small chart drawn inside a table cell at the current position, of the values returned by
+// GetSparklineValues. Pass in "type" string as "line", "bar" or "winloss", "width" and "height" float for the cell, which
+// grows to the height of the row, "padding" float around the chart, "border" string and "fill" bool as for CellFormat,
+// "color" and "negativecolor" strings for the line or positive bars and for negative bars, "markers" bool to mark the
+// lowest, highest and last points of a line, "linewidth" float in points and "min" and "max" float to fix the range.
+// The cell height counts towards the row height calculated before rendering the row.
+// Defaults are "type": "line", "data": "", "target": "", "loop": false, "width": 0.0, "height": 5.0, "padding": 0.5,
+// "border": "", "fill": false, "color": "#4e79a7", "negativecolor": "#e15759", "markers": false, "linewidth": 0.75
+func (p *JSONGOFPDF) Sparkline(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
+	sparklineType := p.GetString("type", logic, "line")
+	width := p.GetFloat("width", logic, 0.0)
+	height := p.GetFloat("height", logic, 5.0)
+	padding := p.GetFloat("padding", logic, 0.5)
+	border := p.GetString("border", logic, "")
+	fill := p.GetBool("fill", logic, false)
+	color := p.GetString("color", logic, "#4e79a7")
+	negativeColor := p.GetString("negativecolor", logic, "#e15759")
+	markers := p.GetBool("markers", logic, false)
+	lineWidth := p.GetFloat("linewidth", logic, 0.75) / pdf.GetConversionRatio()
+
+	x, y := pdf.GetXY()
+	if width == 0 {
+		pageWidth, _ := pdf.GetPageSize()
+		_, _, rightMargin, _ := pdf.GetMargins()
+		width = pageWidth - rightMargin - x
+	}
+	cellHeight := math.Max(height, p.rowHeight())
+	if border != "" || fill {
+		pdf.CellFormat(width, cellHeight, "", border, 0, "", fill, 0, "")
+	}
+
+	values := p.GetSparklineValues(logic)
+	box := chartBox{x: x + padding, y: y + (cellHeight-height)/2 + padding, width: width - 2*padding, height: height - 2*padding}
+	if len(values) > 0 && box.width > 0 && box.height > 0 {
+		restore := p.saveGraphics(pdf)
+		p.drawSparkline(pdf, box, values, sparklineType, logic, color, negativeColor, markers, lineWidth)
+		restore()
+	}
+
+	pdf.SetXY(x, y+cellHeight)
+	if pdf.GetY() > p.NextY {
+		p.NextY = pdf.GetY()
+	}
+	return pdf
+}
+
+func (p *JSONGOFPDF) drawSparkline(pdf *gofpdf.Fpdf, box chartBox, values []float64, sparklineType string, logic string, color string, negativeColor string, markers bool, lineWidth float64) {
+	count := float64(len(values))
+	if sparklineType == "winloss" {
+		// Wins rise from the middle and losses fall from it, all the same height
+		step := box.width / count
+		middle := box.y + box.height/2
+		for index, value := range values {
+			if value == 0 {
+				continue
+			}
+			setChartColor(pdf, color)
+			top := box.y
+			if value < 0 {
+				setChartColor(pdf, negativeColor)
+				top = middle + box.height*0.05
+			}
+			pdf.Rect(box.x+float64(index)*step+step*0.1, top, step*0.8, box.height*0.45, "F")
+		}
+		return
+	}
+
+	minimum, maximum := values[0], values[0]
+	for _, value := range values {
+		minimum, maximum = math.Min(minimum, value), math.Max(maximum, value)
+	}
+	if sparklineType == "bar" {
+		minimum, maximum = math.Min(minimum, 0), math.Max(maximum, 0)
+	}
+	minimum = p.GetFloat("min", logic, minimum)
+	maximum = p.GetFloat("max", logic, maximum)
+	if maximum == minimum {
+		maximum, minimum = maximum+1, minimum-1
+	}
+	valueY := func(value float64) float64 {
+		value = math.Max(minimum, math.Min(maximum, value))
+		return box.y + box.height - (value-minimum)/(maximum-minimum)*box.height
+	}
+
+	if sparklineType == "bar" {
+		step := box.width / count
+		zero := valueY(0)
+		for index, value := range values {
+			setChartColor(pdf, color)
+			if value < 0 {
+				setChartColor(pdf, negativeColor)
+			}
+			top := valueY(value)
+			pdf.Rect(box.x+float64(index)*step+step*0.1, math.Min(top, zero), step*0.8, math.Abs(zero-top), "F")
+		}
+		return
+	}
+
+	step := 0.0
+	if count > 1 {
+		step = box.width / (count - 1)
+	}
+	setChartColor(pdf, color)
+	pdf.SetLineWidth(lineWidth)
+	pdf.SetLineJoinStyle("round")
+	pdf.MoveTo(box.x, valueY(values[0]))
+	for index, value := range values[1:] {
+		pdf.LineTo(box.x+float64(index+1)*step, valueY(value))
+	}
+	pdf.DrawPath("D")
+
+	if markers {
+		lowest, highest := 0, 0
+		for index, value := range values {
+			if value < values[lowest] {
+				lowest = index
+			}
+			if value > values[highest] {
+				highest = index
+			}
+		}
+		radius := lineWidth * 1.5
+		setChartColor(pdf, negativeColor)
+		pdf.Circle(box.x+float64(lowest)*step, valueY(values[lowest]), radius, "F")
+		setChartColor(pdf, color)
+		pdf.Circle(box.x+float64(highest)*step, valueY(values[highest]), radius, "F")
+		last := len(values) - 1
+		pdf.Circle(box.x+float64(last)*step, valueY(values[last]), radius, "F")
+	}
+}
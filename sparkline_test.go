@@ -0,0 +1,37 @@
+package jsongofpdf
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetSparklineValues(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		data  string
+		logic string
+		want  []float64
+	}{
+		{"json array", "[1, 2.5, -3]", "", `{}`, []float64{1, 2.5, -3}},
+		{"comma separated", " 4, 5 ,,6 ", "", `{}`, []float64{4, 5, 6}},
+		{"decoded array", []interface{}{1, "2", 3.5}, "", `{}`, []float64{1, 2, 3.5}},
+		{"target", "7,8", "", `{"target": "trend"}`, []float64{7, 8}},
+		{"data path", "", `{"sales":{"months":[10,20,15]}}`, `{"data": "sales.months"}`, []float64{10, 20, 15}},
+		{"data path string", "", `{"results":"1,-1,1"}`, `{"data": "results"}`, []float64{1, -1, 1}},
+		{"win loss", "1,-1,0,-2,3", "", `{"type": "winloss"}`, []float64{1, -1, 0, -2, 3}},
+		{"missing data path", "1,2", `{}`, `{"data": "sales"}`, nil},
+		{"empty", "", "", `{}`, nil},
+	}
+
+	for _, test := range tests {
+		p := &JSONGOFPDF{}
+		p.Tables = []Table{{
+			Rows: []Row{{Cells: []Cell{{Key: "trend", Path: "trend", Value: test.value}}}},
+			Data: []string{test.data},
+		}}
+		if values := p.GetSparklineValues(test.logic); !reflect.DeepEqual(values, test.want) {
+			t.Fatalf("%s should return %v, got %v", test.name, test.want, values)
+		}
+	}
+}
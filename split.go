@@ -0,0 +1,148 @@
+package jsongofpdf
+
+import (
+	"reflect"
+	"unsafe"
+
+	"github.com/buger/jsonparser"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// rowSplit is a table row rendered across pages. Every cell of the row starts at the top of the row and continues on the
+// pages the cells before it broke onto, and the row ends at the lowest point any cell reached.
+type rowSplit struct {
+	page       int
+	y          float64
+	bottomPage int
+	bottomY    float64
+}
+
+// track moves the bottom of the row down to the current position when it is lower.
+func (s *rowSplit) track(pdf *gofpdf.Fpdf) {
+	page, y := pdf.PageNo(), pdf.GetY()
+	if page > s.bottomPage || (page == s.bottomPage && y > s.bottomY) {
+		s.bottomPage, s.bottomY = page, y
+	}
+}
+
+// RowBreak decides how the current row, measured by the pre-render pipeline, is placed when it does not fit on the page.
+// A row kept together moves to the next page, with the auto page break margin honoured, unless it is taller than a page.
+// It returns whether the row is to be split across pages, which rows that allow splitting and rows too tall to keep
+// together are. Rows that neither keep together nor allow splitting are left to the automatic page break of each cell.
+func (p *JSONGOFPDF) RowBreak(pdf *gofpdf.Fpdf, keepTogether bool, allowSplit bool) bool {
+	auto, margin := pdf.GetAutoPageBreak()
+	if !auto {
+		return false
+	}
+	_, pageHeight := pdf.GetPageSize()
+	trigger := pageHeight - margin
+	if p.CurrentRowY+p.RowHeight <= trigger {
+		return false
+	}
+
+	if keepTogether && p.RowHeight <= trigger-p.freshPageTop(pdf) {
+		pdf.SetY(p.CurrentRowY)
+		p.PageBreak(pdf, p.RowHeight)
+		p.CurrentRowY = pdf.GetY()
+		return false
+	}
+	return keepTogether || allowSplit
+}
+
+// freshPageTop is where the rows of a table start on a new page, below the page and table headers.
+func (p *JSONGOFPDF) freshPageTop(pdf *gofpdf.Fpdf) float64 {
+	if p.pageTop > 0 {
+		return p.pageTop
+	}
+	_, top, _, _ := pdf.GetMargins()
+	return top + p.tableHeaderHeight
+}
+
+// SplitRow runs the operations of the current row one by one, continuing each cell on the pages the row has already
+// broke onto rather than adding new ones, and moves to the bottom of the row once all are done.
+func (p *JSONGOFPDF) SplitRow(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
+	p.rowSplit = &rowSplit{page: pdf.PageNo(), y: p.CurrentRowY, bottomPage: pdf.PageNo(), bottomY: p.CurrentRowY}
+	accept := acceptPageBreakFunc(pdf)
+	pdf.SetAcceptPageBreakFunc(func() bool {
+		return p.acceptRowBreak(pdf, accept)
+	})
+
+	jsonparser.ArrayEach([]byte(logic), func(value []byte, dataType jsonparser.ValueType, offset int, err error) {
+		switch dataType {
+		case jsonparser.Object:
+			pdf = p.RunObjectOperations(pdf, string(value))
+			p.rowSplit.track(pdf)
+			break
+		}
+	})
+
+	split := p.rowSplit
+	p.rowSplit = nil
+	if accept != nil {
+		pdf.SetAcceptPageBreakFunc(accept)
+	}
+	if pdf.PageNo() != split.bottomPage {
+		pdf.SetPage(split.bottomPage)
+		restorePageState(pdf)
+	}
+	pdf.SetY(split.bottomY)
+	p.NextY = split.bottomY
+	return pdf
+}
+
+// acceptRowBreak continues a cell of a split row on the next page when the row has already broken onto it, and leaves
+// adding a page to accept, the function set before the row, otherwise.
+func (p *JSONGOFPDF) acceptRowBreak(pdf *gofpdf.Fpdf, accept func() bool) bool {
+	if auto, _ := pdf.GetAutoPageBreak(); auto && p.rowSplit != nil && pdf.PageNo() < pdf.PageCount() {
+		p.rowSplit.track(pdf)
+		x := pdf.GetX()
+		pdf.SetPage(pdf.PageNo() + 1)
+		restorePageState(pdf)
+		top, ok := p.pageTops[pdf.PageNo()]
+		if !ok {
+			_, top, _, _ = pdf.GetMargins()
+		}
+		pdf.SetXY(x, top)
+		return false
+	}
+	if accept != nil {
+		return accept()
+	}
+	auto, _ := pdf.GetAutoPageBreak()
+	return auto
+}
+
+// acceptPageBreakFunc returns the function set by SetAcceptPageBreakFunc, or nil when there is none. gofpdf does not
+// export it, so it is read by reflection.
+func acceptPageBreakFunc(pdf *gofpdf.Fpdf) func() bool {
+	field := reflect.ValueOf(pdf).Elem().FieldByName("acceptPageBreak")
+	if !field.IsValid() || field.IsNil() {
+		return nil
+	}
+	accept, _ := reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem().Interface().(func() bool)
+	return accept
+}
+
+// rowSplitStart moves to the top of the current row, on the page it started on when it is split across pages.
+func (p *JSONGOFPDF) rowSplitStart(pdf *gofpdf.Fpdf) {
+	if p.rowSplit != nil && pdf.PageNo() != p.rowSplit.page {
+		pdf.SetPage(p.rowSplit.page)
+		restorePageState(pdf)
+	}
+	if p.rowSplit != nil {
+		pdf.SetY(p.rowSplit.y)
+		return
+	}
+	pdf.SetY(p.CurrentRowY)
+}
+
+// restorePageState writes the current font, colours and line width to a page returned to, as the state at the end of its
+// content may differ from the state of the page left. SetFont skips a font that is already current, but SetFontSize always
+// writes the current font.
+func restorePageState(pdf *gofpdf.Fpdf) {
+	fontSize, _ := pdf.GetFontSize()
+	pdf.SetFontSize(fontSize)
+	pdf.SetDrawColor(pdf.GetDrawColor())
+	pdf.SetFillColor(pdf.GetFillColor())
+	pdf.SetLineWidth(pdf.GetLineWidth())
+}
@@ -0,0 +1,120 @@
+package jsongofpdf
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// linesTable is a table with a row for each count, its item cell having that many lines of text.
+func linesTable(counts ...int) (table Table) {
+	for row, count := range counts {
+		lines := make([]string, count)
+		for line := range lines {
+			lines[line] = fmt.Sprintf("row %d line %d", row+1, line+1)
+		}
+		table.Rows = append(table.Rows, Row{Cells: []Cell{
+			{Key: "item", Path: "item", Title: "Item", Value: strings.Join(lines, "<br>")},
+			{Key: "amount", Path: "amount", Title: "Amount", Value: row + 1},
+		}})
+		table.Data = append(table.Data, fmt.Sprintf(`{"amount":%d}`, row+1))
+	}
+	return table
+}
+
+func TestRowBreak(t *testing.T) {
+	rows := []int{7, 7, 7, 7, 7, 7, 7, 7, 7, 7}
+	tests := []struct {
+		name         string
+		keepTogether bool
+		allowSplit   bool
+		counts       []int
+		split        bool
+	}{
+		{"kept together", true, false, rows, false},
+		{"split", false, true, rows, true},
+		{"taller than a page", true, false, []int{3, 60, 3}, true},
+	}
+
+	for _, test := range tests {
+		logic := fmt.Sprintf(`[{"new": {}}, {"setfont": {"size": 10}}, {"addpage": {}}, {"tablefunc": {"header": %s,
+			"keeptogether": %v, "allowsplit": %v, "body": [{"row": %s}]}}]`, amountHeader, test.keepTogether, test.allowSplit, amountRow)
+		pages := renderPages(t, logic, []Table{linesTable(test.counts...)})
+
+		found := make([]string, 0)
+		rowPages := make(map[int]map[int]bool)
+		firstLines := make(map[int][2]float64)
+		amounts := make(map[int][2]float64)
+		for index, page := range pages {
+			for _, text := range page {
+				var row, line int
+				if _, err := fmt.Sscanf(text.text, "row %d line %d", &row, &line); err != nil {
+					if _, err := fmt.Sscanf(text.text, "%d", &row); err == nil {
+						amounts[row] = [2]float64{float64(index), text.y}
+					}
+					continue
+				}
+				if text.y < 10 || text.y > 297-20 {
+					t.Fatalf("%s: %q at %.1fmm on page %d is outside the margins", test.name, text.text, text.y, index+1)
+				}
+				if line == 1 {
+					firstLines[row] = [2]float64{float64(index), text.y}
+				}
+				found = append(found, text.text)
+				if rowPages[row] == nil {
+					rowPages[row] = make(map[int]bool)
+				}
+				rowPages[row][index] = true
+			}
+		}
+		for row, first := range firstLines {
+			if amounts[row] != first {
+				t.Fatalf("%s: the cells of row %d should start side by side, got page and y %v and %v", test.name, row, first, amounts[row])
+			}
+		}
+
+		want := make([]string, 0)
+		for row, count := range test.counts {
+			for line := 1; line <= count; line++ {
+				want = append(want, fmt.Sprintf("row %d line %d", row+1, line))
+			}
+		}
+		if strings.Join(found, ",") != strings.Join(want, ",") {
+			t.Fatalf("%s: every line should be rendered once and in order, got %v", test.name, found)
+		}
+
+		split := false
+		for _, onPages := range rowPages {
+			split = split || len(onPages) > 1
+		}
+		if split != test.split {
+			t.Fatalf("%s: rows should be split across pages %v, got %v", test.name, test.split, split)
+		}
+	}
+}
+
+func TestSplitRowAcceptPageBreak(t *testing.T) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetFont("Arial", "", 10)
+	pdf.AddPage()
+	calls := 0
+	pdf.SetAcceptPageBreakFunc(func() bool {
+		calls++
+		return true
+	})
+
+	p := &JSONGOFPDF{CurrentRowY: 250, tr: pdf.UnicodeTranslatorFromDescriptor("")}
+	pdf.SetY(250)
+	p.SplitRow(pdf, `[{"multicell": {"text": "`+strings.Repeat("line<br>", 20)+`", "width": 40, "height": 5}}]`)
+	if pdf.PageCount() != 2 || calls != 1 {
+		t.Fatalf("a row breaking onto a new page should ask the page break function set before it, got %d pages and %d calls", pdf.PageCount(), calls)
+	}
+
+	pdf.SetY(290)
+	pdf.MultiCell(40, 5, "after", "", "", false)
+	if pdf.PageCount() != 3 || calls != 2 {
+		t.Fatalf("the page break function set before the row should be restored, got %d pages and %d calls", pdf.PageCount(), calls)
+	}
+}
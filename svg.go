@@ -0,0 +1,888 @@
+package jsongofpdf
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/buger/jsonparser"
+	"github.com/jung-kurt/gofpdf"
+)
+
+var (
+	ErrSVGFormat = errors.New("Invalid svg")
+)
+
+// SVG is a parsed svg document of Width by Height user units. Shapes are flattened to absolute move, line, cubic curve and
+// close segments in document coordinates with the view box, group transforms and inherited styles already applied.
+type SVG struct {
+	Width  float64
+	Height float64
+	Shapes []SVGShape
+}
+
+// SVGShape is a single filled and or stroked path of an SVG.
+type SVGShape struct {
+	Segments      []SVGSegment
+	Fill          string
+	Stroke        string
+	StrokeWidth   float64
+	FillRule      string
+	Opacity       float64
+	FillOpacity   float64
+	StrokeOpacity float64
+	LineCap       string
+	LineJoin      string
+}
+
+// SVGSegment is an absolute path segment. Cmd is 'M' and 'L' with one point, 'C' with two control points and the end point
+// or 'Z' with none.
+type SVGSegment struct {
+	Cmd    byte
+	Points []float64
+}
+
+// svgStyle holds the inherited presentation attributes while parsing.
+type svgStyle struct {
+	fill          string
+	stroke        string
+	strokeWidth   float64
+	fillRule      string
+	opacity       float64
+	fillOpacity   float64
+	strokeOpacity float64
+	lineCap       string
+	lineJoin      string
+	matrix        [6]float64
+}
+
+// IsSVG reports whether data looks like an svg document.
+func IsSVG(data []byte) bool {
+	head := data
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	return bytes.Contains(bytes.ToLower(head), []byte("<svg"))
+}
+
+// ParseSVG parses svg paths, rects, circles, ellipses, lines, polylines and polygons, including those nested in groups with
+// transforms and fill, stroke and opacity styling. Gradients, text, clipping and referenced elements are not supported.
+func ParseSVG(data []byte) (*SVG, error) {
+	svg := &SVG{}
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+
+	styles := []svgStyle{{
+		fill:          "black",
+		stroke:        "none",
+		strokeWidth:   1,
+		fillRule:      "nonzero",
+		opacity:       1,
+		fillOpacity:   1,
+		strokeOpacity: 1,
+		matrix:        [6]float64{1, 0, 0, 1, 0, 0},
+	}}
+	root := false
+	skip := 0
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+
+		switch element := token.(type) {
+		case xml.StartElement:
+			if skip > 0 {
+				skip++
+				continue
+			}
+			attributes := make(map[string]string)
+			for _, attribute := range element.Attr {
+				attributes[attribute.Name.Local] = attribute.Value
+			}
+			for _, declaration := range strings.Split(attributes["style"], ";") {
+				if parts := strings.SplitN(declaration, ":", 2); len(parts) == 2 {
+					attributes[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+				}
+			}
+
+			name := strings.ToLower(element.Name.Local)
+			switch name {
+			case "defs", "title", "desc", "style", "metadata", "clippath", "mask", "lineargradient", "radialgradient", "pattern", "symbol", "marker", "text":
+				skip = 1
+				continue
+			}
+
+			style := svgInheritStyle(styles[len(styles)-1], attributes)
+			styles = append(styles, style)
+
+			if name == "svg" && !root {
+				root = true
+				svg.Width = svgLength(attributes["width"])
+				svg.Height = svgLength(attributes["height"])
+				viewBox := svgNumbers(attributes["viewBox"])
+				if len(viewBox) == 4 && viewBox[2] > 0 && viewBox[3] > 0 {
+					if svg.Width == 0 || svg.Height == 0 {
+						svg.Width, svg.Height = viewBox[2], viewBox[3]
+					}
+					// Map the view box onto the width and height of the document
+					scaleX, scaleY := svg.Width/viewBox[2], svg.Height/viewBox[3]
+					style.matrix = svgMultiply([6]float64{scaleX, 0, 0, scaleY, -viewBox[0] * scaleX, -viewBox[1] * scaleY}, style.matrix)
+					styles[len(styles)-1] = style
+				}
+				continue
+			}
+
+			segments := svgShapeSegments(name, attributes)
+			if len(segments) == 0 {
+				continue
+			}
+			for i, segment := range segments {
+				points := make([]float64, len(segment.Points))
+				for j := 0; j+1 < len(segment.Points); j += 2 {
+					points[j], points[j+1] = svgApply(style.matrix, segment.Points[j], segment.Points[j+1])
+				}
+				segments[i].Points = points
+			}
+			svg.Shapes = append(svg.Shapes, SVGShape{
+				Segments:      segments,
+				Fill:          style.fill,
+				Stroke:        style.stroke,
+				StrokeWidth:   style.strokeWidth * math.Sqrt(math.Abs(style.matrix[0]*style.matrix[3]-style.matrix[1]*style.matrix[2])),
+				FillRule:      style.fillRule,
+				Opacity:       style.opacity,
+				FillOpacity:   style.fillOpacity,
+				StrokeOpacity: style.strokeOpacity,
+				LineCap:       style.lineCap,
+				LineJoin:      style.lineJoin,
+			})
+			break
+		case xml.EndElement:
+			if skip > 0 {
+				skip--
+				continue
+			}
+			if len(styles) > 1 {
+				styles = styles[:len(styles)-1]
+			}
+			break
+		}
+	}
+
+	if !root {
+		return nil, ErrSVGFormat
+	}
+	if svg.Width == 0 || svg.Height == 0 {
+		svg.Width, svg.Height = 100, 100
+	}
+	return svg, nil
+}
+
+// svgInheritStyle applies the presentation attributes of an element over the style of its parent.
+func svgInheritStyle(style svgStyle, attributes map[string]string) svgStyle {
+	if value, ok := attributes["fill"]; ok {
+		style.fill = value
+	}
+	if value, ok := attributes["stroke"]; ok {
+		style.stroke = value
+	}
+	if value, ok := attributes["stroke-width"]; ok {
+		style.strokeWidth = svgLength(value)
+	}
+	if value, ok := attributes["fill-rule"]; ok {
+		style.fillRule = value
+	}
+	if value, ok := attributes["opacity"]; ok {
+		style.opacity *= svgOpacity(value)
+	}
+	if value, ok := attributes["fill-opacity"]; ok {
+		style.fillOpacity = svgOpacity(value)
+	}
+	if value, ok := attributes["stroke-opacity"]; ok {
+		style.strokeOpacity = svgOpacity(value)
+	}
+	if value, ok := attributes["stroke-linecap"]; ok {
+		style.lineCap = value
+	}
+	if value, ok := attributes["stroke-linejoin"]; ok {
+		style.lineJoin = value
+	}
+	if value, ok := attributes["transform"]; ok {
+		style.matrix = svgMultiply(style.matrix, svgTransform(value))
+	}
+	return style
+}
+
+// svgShapeSegments converts a basic shape or path element to absolute segments in its own coordinates.
+func svgShapeSegments(name string, attributes map[string]string) []SVGSegment {
+	number := func(key string) float64 {
+		return svgLength(attributes[key])
+	}
+
+	switch name {
+	case "path":
+		return svgPathSegments(attributes["d"])
+	case "rect":
+		x, y, width, height := number("x"), number("y"), number("width"), number("height")
+		if width <= 0 || height <= 0 {
+			return nil
+		}
+		rx, rxOk := attributes["rx"]
+		ry, ryOk := attributes["ry"]
+		radiusX, radiusY := svgLength(rx), svgLength(ry)
+		if !ryOk {
+			radiusY = radiusX
+		}
+		if !rxOk {
+			radiusX = radiusY
+		}
+		radiusX, radiusY = math.Min(radiusX, width/2), math.Min(radiusY, height/2)
+		if radiusX <= 0 || radiusY <= 0 {
+			return []SVGSegment{
+				{'M', []float64{x, y}}, {'L', []float64{x + width, y}}, {'L', []float64{x + width, y + height}},
+				{'L', []float64{x, y + height}}, {'Z', nil},
+			}
+		}
+		d := fmt.Sprintf("M%g %gH%gA%g %g 0 0 1 %g %gV%gA%g %g 0 0 1 %g %gH%gA%g %g 0 0 1 %g %gV%gA%g %g 0 0 1 %g %gZ",
+			x+radiusX, y, x+width-radiusX, radiusX, radiusY, x+width, y+radiusY, y+height-radiusY, radiusX, radiusY, x+width-radiusX, y+height,
+			x+radiusX, radiusX, radiusY, x, y+height-radiusY, y+radiusY, radiusX, radiusY, x+radiusX, y)
+		return svgPathSegments(d)
+	case "circle":
+		return svgEllipseSegments(number("cx"), number("cy"), number("r"), number("r"))
+	case "ellipse":
+		return svgEllipseSegments(number("cx"), number("cy"), number("rx"), number("ry"))
+	case "line":
+		return []SVGSegment{{'M', []float64{number("x1"), number("y1")}}, {'L', []float64{number("x2"), number("y2")}}}
+	case "polyline", "polygon":
+		points := svgNumbers(attributes["points"])
+		if len(points) < 4 {
+			return nil
+		}
+		segments := []SVGSegment{{'M', points[0:2]}}
+		for i := 2; i+1 < len(points); i += 2 {
+			segments = append(segments, SVGSegment{'L', points[i : i+2]})
+		}
+		if name == "polygon" {
+			segments = append(segments, SVGSegment{'Z', nil})
+		}
+		return segments
+	}
+	return nil
+}
+
+// svgEllipseSegments approximates an ellipse with four cubic curves.
+func svgEllipseSegments(cx float64, cy float64, rx float64, ry float64) []SVGSegment {
+	if rx <= 0 || ry <= 0 {
+		return nil
+	}
+	kx, ky := rx*0.5522847498, ry*0.5522847498
+	return []SVGSegment{
+		{'M', []float64{cx + rx, cy}},
+		{'C', []float64{cx + rx, cy + ky, cx + kx, cy + ry, cx, cy + ry}},
+		{'C', []float64{cx - kx, cy + ry, cx - rx, cy + ky, cx - rx, cy}},
+		{'C', []float64{cx - rx, cy - ky, cx - kx, cy - ry, cx, cy - ry}},
+		{'C', []float64{cx + kx, cy - ry, cx + rx, cy - ky, cx + rx, cy}},
+		{'Z', nil},
+	}
+}
+
+// svgPathSegments parses path data, converting relative, shorthand, quadratic and arc commands to absolute segments.
+func svgPathSegments(d string) []SVGSegment {
+	segments := make([]SVGSegment, 0)
+	position, length := 0, len(d)
+	var cmd byte
+	x, y, startX, startY := 0.0, 0.0, 0.0, 0.0
+	lastControlX, lastControlY := 0.0, 0.0
+	var lastCmd byte
+
+	skipSeparators := func() {
+		for position < length && (d[position] == ' ' || d[position] == ',' || d[position] == '\t' || d[position] == '\n' || d[position] == '\r') {
+			position++
+		}
+	}
+	readNumber := func() (float64, bool) {
+		skipSeparators()
+		start := position
+		if position < length && (d[position] == '-' || d[position] == '+') {
+			position++
+		}
+		dot, digits := false, false
+		for position < length {
+			char := d[position]
+			if char >= '0' && char <= '9' {
+				digits = true
+			} else if char == '.' && !dot {
+				dot = true
+			} else if (char == 'e' || char == 'E') && digits {
+				position++
+				if position < length && (d[position] == '-' || d[position] == '+') {
+					position++
+				}
+				continue
+			} else {
+				break
+			}
+			position++
+		}
+		if !digits {
+			position = start
+			return 0, false
+		}
+		value, err := strconv.ParseFloat(d[start:position], 64)
+		return value, err == nil
+	}
+	readFlag := func() (bool, bool) {
+		skipSeparators()
+		if position < length && (d[position] == '0' || d[position] == '1') {
+			position++
+			return d[position-1] == '1', true
+		}
+		return false, false
+	}
+	readNumbers := func(count int) ([]float64, bool) {
+		values := make([]float64, count)
+		for i := range values {
+			value, ok := readNumber()
+			if !ok {
+				return nil, false
+			}
+			values[i] = value
+		}
+		return values, true
+	}
+
+	for {
+		skipSeparators()
+		if position >= length {
+			break
+		}
+		if char := d[position]; strings.IndexByte("MmLlHhVvCcSsQqTtAaZz", char) >= 0 {
+			cmd = char
+			position++
+		} else if cmd == 0 {
+			break
+		}
+
+		// A subpath drawn straight after a close starts again at the start of the closed subpath
+		if len(segments) > 0 && segments[len(segments)-1].Cmd == 'Z' && cmd != 'M' && cmd != 'm' && cmd != 'Z' && cmd != 'z' {
+			segments = append(segments, SVGSegment{'M', []float64{x, y}})
+		}
+
+		relative := cmd >= 'a' && cmd <= 'z'
+		offsetX, offsetY := 0.0, 0.0
+		if relative {
+			offsetX, offsetY = x, y
+		}
+
+		switch cmd {
+		case 'M', 'm':
+			values, ok := readNumbers(2)
+			if !ok {
+				return segments
+			}
+			x, y = values[0]+offsetX, values[1]+offsetY
+			startX, startY = x, y
+			segments = append(segments, SVGSegment{'M', []float64{x, y}})
+			// Further coordinate pairs are implicit line commands
+			if relative {
+				cmd = 'l'
+			} else {
+				cmd = 'L'
+			}
+			lastCmd = 'M'
+			continue
+		case 'L', 'l':
+			values, ok := readNumbers(2)
+			if !ok {
+				return segments
+			}
+			x, y = values[0]+offsetX, values[1]+offsetY
+			segments = append(segments, SVGSegment{'L', []float64{x, y}})
+			break
+		case 'H', 'h':
+			value, ok := readNumber()
+			if !ok {
+				return segments
+			}
+			x = value + offsetX
+			segments = append(segments, SVGSegment{'L', []float64{x, y}})
+			break
+		case 'V', 'v':
+			value, ok := readNumber()
+			if !ok {
+				return segments
+			}
+			y = value + offsetY
+			segments = append(segments, SVGSegment{'L', []float64{x, y}})
+			break
+		case 'C', 'c':
+			values, ok := readNumbers(6)
+			if !ok {
+				return segments
+			}
+			points := []float64{values[0] + offsetX, values[1] + offsetY, values[2] + offsetX, values[3] + offsetY, values[4] + offsetX, values[5] + offsetY}
+			segments = append(segments, SVGSegment{'C', points})
+			lastControlX, lastControlY = points[2], points[3]
+			x, y = points[4], points[5]
+			lastCmd = 'C'
+			continue
+		case 'S', 's':
+			values, ok := readNumbers(4)
+			if !ok {
+				return segments
+			}
+			controlX, controlY := x, y
+			if lastCmd == 'C' {
+				controlX, controlY = 2*x-lastControlX, 2*y-lastControlY
+			}
+			points := []float64{controlX, controlY, values[0] + offsetX, values[1] + offsetY, values[2] + offsetX, values[3] + offsetY}
+			segments = append(segments, SVGSegment{'C', points})
+			lastControlX, lastControlY = points[2], points[3]
+			x, y = points[4], points[5]
+			lastCmd = 'C'
+			continue
+		case 'Q', 'q', 'T', 't':
+			controlX, controlY := x, y
+			var endX, endY float64
+			if cmd == 'Q' || cmd == 'q' {
+				values, ok := readNumbers(4)
+				if !ok {
+					return segments
+				}
+				controlX, controlY = values[0]+offsetX, values[1]+offsetY
+				endX, endY = values[2]+offsetX, values[3]+offsetY
+			} else {
+				values, ok := readNumbers(2)
+				if !ok {
+					return segments
+				}
+				if lastCmd == 'Q' {
+					controlX, controlY = 2*x-lastControlX, 2*y-lastControlY
+				}
+				endX, endY = values[0]+offsetX, values[1]+offsetY
+			}
+			// Quadratic curves are raised to cubic curves
+			segments = append(segments, SVGSegment{'C', []float64{
+				x + 2.0/3.0*(controlX-x), y + 2.0/3.0*(controlY-y),
+				endX + 2.0/3.0*(controlX-endX), endY + 2.0/3.0*(controlY-endY),
+				endX, endY,
+			}})
+			lastControlX, lastControlY = controlX, controlY
+			x, y = endX, endY
+			lastCmd = 'Q'
+			continue
+		case 'A', 'a':
+			radii, ok := readNumbers(3)
+			if !ok {
+				return segments
+			}
+			largeArc, ok := readFlag()
+			if !ok {
+				return segments
+			}
+			sweep, ok := readFlag()
+			if !ok {
+				return segments
+			}
+			end, ok := readNumbers(2)
+			if !ok {
+				return segments
+			}
+			endX, endY := end[0]+offsetX, end[1]+offsetY
+			segments = append(segments, svgArcSegments(x, y, radii[0], radii[1], radii[2], largeArc, sweep, endX, endY)...)
+			x, y = endX, endY
+			break
+		case 'Z', 'z':
+			segments = append(segments, SVGSegment{'Z', nil})
+			x, y = startX, startY
+			cmd = 0
+			break
+		}
+		lastCmd = 'L'
+	}
+
+	return segments
+}
+
+// svgArcSegments converts an svg elliptical arc from x1, y1 to x2, y2 into cubic curves, following the svg implementation notes.
+func svgArcSegments(x1 float64, y1 float64, rx float64, ry float64, angle float64, largeArc bool, sweep bool, x2 float64, y2 float64) []SVGSegment {
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 || (x1 == x2 && y1 == y2) {
+		return []SVGSegment{{'L', []float64{x2, y2}}}
+	}
+
+	phi := angle * math.Pi / 180
+	cosPhi, sinPhi := math.Cos(phi), math.Sin(phi)
+	dx, dy := (x1-x2)/2, (y1-y2)/2
+	x1p := cosPhi*dx + sinPhi*dy
+	y1p := -sinPhi*dx + cosPhi*dy
+
+	// Scale up radii that are too small to reach the end point
+	if lambda := x1p*x1p/(rx*rx) + y1p*y1p/(ry*ry); lambda > 1 {
+		rx, ry = rx*math.Sqrt(lambda), ry*math.Sqrt(lambda)
+	}
+
+	numerator := rx*rx*ry*ry - rx*rx*y1p*y1p - ry*ry*x1p*x1p
+	denominator := rx*rx*y1p*y1p + ry*ry*x1p*x1p
+	factor := math.Sqrt(math.Max(0, numerator/denominator))
+	if largeArc == sweep {
+		factor = -factor
+	}
+	cxp, cyp := factor*rx*y1p/ry, -factor*ry*x1p/rx
+	cx := cosPhi*cxp - sinPhi*cyp + (x1+x2)/2
+	cy := sinPhi*cxp + cosPhi*cyp + (y1+y2)/2
+
+	vectorAngle := func(ux, uy, vx, vy float64) float64 {
+		return math.Atan2(ux*vy-uy*vx, ux*vx+uy*vy)
+	}
+	theta := vectorAngle(1, 0, (x1p-cxp)/rx, (y1p-cyp)/ry)
+	delta := vectorAngle((x1p-cxp)/rx, (y1p-cyp)/ry, (-x1p-cxp)/rx, (-y1p-cyp)/ry)
+	if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	} else if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	}
+
+	count := int(math.Ceil(math.Abs(delta) / (math.Pi / 2)))
+	step := delta / float64(count)
+	k := 4.0 / 3.0 * math.Tan(step/4)
+	point := func(t float64) (float64, float64) {
+		px, py := rx*math.Cos(t), ry*math.Sin(t)
+		return cx + cosPhi*px - sinPhi*py, cy + sinPhi*px + cosPhi*py
+	}
+	derivative := func(t float64) (float64, float64) {
+		px, py := -rx*math.Sin(t), ry*math.Cos(t)
+		return cosPhi*px - sinPhi*py, sinPhi*px + cosPhi*py
+	}
+
+	segments := make([]SVGSegment, 0, count)
+	for i := 0; i < count; i++ {
+		start, end := theta+float64(i)*step, theta+float64(i+1)*step
+		startX, startY := point(start)
+		endX, endY := point(end)
+		startDX, startDY := derivative(start)
+		endDX, endDY := derivative(end)
+		if i == count-1 {
+			endX, endY = x2, y2
+		}
+		segments = append(segments, SVGSegment{'C', []float64{
+			startX + k*startDX, startY + k*startDY,
+			endX - k*endDX, endY - k*endDY,
+			endX, endY,
+		}})
+	}
+	return segments
+}
+
+// svgTransform parses a transform list into an affine matrix a, b, c, d, e, f.
+func svgTransform(value string) [6]float64 {
+	matrix := [6]float64{1, 0, 0, 1, 0, 0}
+	for _, part := range strings.Split(value, ")") {
+		open := strings.Index(part, "(")
+		if open < 0 {
+			continue
+		}
+		name := strings.TrimSpace(strings.Trim(strings.TrimSpace(part[:open]), ","))
+		args := svgNumbers(part[open+1:])
+		transform := [6]float64{1, 0, 0, 1, 0, 0}
+		switch name {
+		case "matrix":
+			if len(args) == 6 {
+				copy(transform[:], args)
+			}
+			break
+		case "translate":
+			if len(args) > 0 {
+				transform[4] = args[0]
+			}
+			if len(args) > 1 {
+				transform[5] = args[1]
+			}
+			break
+		case "scale":
+			if len(args) > 0 {
+				transform[0], transform[3] = args[0], args[0]
+			}
+			if len(args) > 1 {
+				transform[3] = args[1]
+			}
+			break
+		case "rotate":
+			if len(args) > 0 {
+				angle := args[0] * math.Pi / 180
+				transform = [6]float64{math.Cos(angle), math.Sin(angle), -math.Sin(angle), math.Cos(angle), 0, 0}
+				if len(args) == 3 {
+					transform = svgMultiply(svgMultiply([6]float64{1, 0, 0, 1, args[1], args[2]}, transform), [6]float64{1, 0, 0, 1, -args[1], -args[2]})
+				}
+			}
+			break
+		case "skewX":
+			if len(args) > 0 {
+				transform[2] = math.Tan(args[0] * math.Pi / 180)
+			}
+			break
+		case "skewY":
+			if len(args) > 0 {
+				transform[1] = math.Tan(args[0] * math.Pi / 180)
+			}
+			break
+		}
+		matrix = svgMultiply(matrix, transform)
+	}
+	return matrix
+}
+
+// svgMultiply returns the matrix applying n and then m.
+func svgMultiply(m [6]float64, n [6]float64) [6]float64 {
+	return [6]float64{
+		m[0]*n[0] + m[2]*n[1],
+		m[1]*n[0] + m[3]*n[1],
+		m[0]*n[2] + m[2]*n[3],
+		m[1]*n[2] + m[3]*n[3],
+		m[0]*n[4] + m[2]*n[5] + m[4],
+		m[1]*n[4] + m[3]*n[5] + m[5],
+	}
+}
+
+func svgApply(m [6]float64, x float64, y float64) (float64, float64) {
+	return m[0]*x + m[2]*y + m[4], m[1]*x + m[3]*y + m[5]
+}
+
+// svgNumbers reads the numbers of a list such as a viewBox, points or transform arguments. Numbers may be separated by
+// whitespace, commas or only by the sign of the next number, such as "10-5".
+func svgNumbers(value string) []float64 {
+	numbers := make([]float64, 0)
+	start := -1
+	flush := func(end int) {
+		if start >= 0 {
+			if number, err := strconv.ParseFloat(value[start:end], 64); err == nil {
+				numbers = append(numbers, number)
+			}
+		}
+		start = -1
+	}
+	for i := 0; i < len(value); i++ {
+		char := value[i]
+		switch {
+		case char == '-' || char == '+':
+			if start >= 0 && value[i-1] != 'e' && value[i-1] != 'E' {
+				flush(i)
+			}
+			if start < 0 {
+				start = i
+			}
+			break
+		case (char >= '0' && char <= '9') || char == '.' || char == 'e' || char == 'E':
+			if start < 0 {
+				start = i
+			}
+			break
+		default:
+			flush(i)
+		}
+	}
+	flush(len(value))
+	return numbers
+}
+
+// svgLength reads a length ignoring its unit, as svg user units and px are treated alike.
+func svgLength(value string) float64 {
+	value = strings.TrimSpace(value)
+	value = strings.TrimRight(value, "abcdefghijklmnopqrstuvwxyz%")
+	number, _ := strconv.ParseFloat(value, 64)
+	return number
+}
+
+func svgOpacity(value string) float64 {
+	value = strings.TrimSpace(value)
+	if strings.HasSuffix(value, "%") {
+		return svgLength(value) / 100
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 1
+	}
+	return math.Max(0, math.Min(1, number))
+}
+
+// DrawSVG draws svg into the box at x, y of width and height, scaled by fit the same way as FitImage. Non empty fill and
+// stroke colours replace the colours of the shapes that are filled or stroked. The size of the box is returned.
+func (p *JSONGOFPDF) DrawSVG(pdf *gofpdf.Fpdf, svg *SVG, x float64, y float64, width float64, height float64, fit string, align string, valign string, fill string, stroke string) (boxWidth float64, boxHeight float64) {
+	// svg user units are css pixels at 96 dpi
+	unit := 72.0 / 96.0 / pdf.GetConversionRatio()
+	box := fitBox(svg.Width*unit, svg.Height*unit, x, y, width, height, fit, align, valign)
+	if box.width <= 0 || box.height <= 0 {
+		return 0, 0
+	}
+	scaleX, scaleY := box.drawWidth/svg.Width, box.drawHeight/svg.Height
+
+	drawR, drawG, drawB := pdf.GetDrawColor()
+	fillR, fillG, fillB := pdf.GetFillColor()
+	lineWidth := pdf.GetLineWidth()
+	alpha, blendMode := pdf.GetAlpha()
+
+	clip := box.drawWidth > box.width+0.001 || box.drawHeight > box.height+0.001
+	if clip {
+		pdf.ClipRect(x, y, box.width, box.height, false)
+	}
+
+	for _, shape := range svg.Shapes {
+		style := ""
+		shapeFill, shapeStroke := shape.Fill, shape.Stroke
+		if fill != "" && shapeFill != "none" {
+			shapeFill = fill
+		}
+		if stroke != "" && shapeStroke != "none" {
+			shapeStroke = stroke
+		}
+		if r, g, b, ok := ParseColor(shapeFill); ok {
+			pdf.SetFillColor(r, g, b)
+			style += "F"
+		}
+		if r, g, b, ok := ParseColor(shapeStroke); ok && shape.StrokeWidth > 0 {
+			pdf.SetDrawColor(r, g, b)
+			pdf.SetLineWidth(shape.StrokeWidth * (scaleX + scaleY) / 2)
+			pdf.SetLineCapStyle(shape.LineCap)
+			pdf.SetLineJoinStyle(shape.LineJoin)
+			style += "D"
+		}
+		if style == "" {
+			continue
+		}
+		if style != "D" && shape.FillRule == "evenodd" {
+			style += "*"
+		}
+
+		opacity := shape.Opacity
+		if style == "F" || style == "F*" {
+			opacity *= shape.FillOpacity
+		} else if style == "D" {
+			opacity *= shape.StrokeOpacity
+		} else {
+			opacity *= math.Min(shape.FillOpacity, shape.StrokeOpacity)
+		}
+		if opacity < 1 {
+			pdf.SetAlpha(opacity, "Normal")
+		}
+
+		for _, segment := range shape.Segments {
+			points := make([]float64, len(segment.Points))
+			for i := 0; i+1 < len(segment.Points); i += 2 {
+				points[i] = box.drawX + segment.Points[i]*scaleX
+				points[i+1] = box.drawY + segment.Points[i+1]*scaleY
+			}
+			switch segment.Cmd {
+			case 'M':
+				pdf.MoveTo(points[0], points[1])
+				break
+			case 'L':
+				pdf.LineTo(points[0], points[1])
+				break
+			case 'C':
+				pdf.CurveBezierCubicTo(points[0], points[1], points[2], points[3], points[4], points[5])
+				break
+			case 'Z':
+				pdf.ClosePath()
+				break
+			}
+		}
+		pdf.DrawPath(style)
+
+		if opacity < 1 {
+			pdf.SetAlpha(alpha, blendMode)
+		}
+	}
+
+	if clip {
+		pdf.ClipEnd()
+	}
+
+	pdf.SetDrawColor(drawR, drawG, drawB)
+	pdf.SetFillColor(fillR, fillG, fillB)
+	pdf.SetLineWidth(lineWidth)
+	pdf.SetLineCapStyle("")
+	pdf.SetLineJoinStyle("")
+
+	return box.width, box.height
+}
+
+// SVG draws an svg scaled into a box. Pass in "src" string as a data uri, a name in the Images or ImageProvider
+// passed to New or a file path, "data" string as a dotted path into the bound Data or "content" string holding the svg
+// markup. Parsed svgs are reused by "name", which defaults to the src or data path. Pass "x", "y", "width", "height" float,
+// "fit", "align", "valign" string and "flow" bool properties as for image, and "fill", "stroke" colour strings to recolour it.
+// Defaults are "src": "", "data": "", "content": "", "name": "", "x": 0.0, "y": 0.0, "width": 0.0, "height": 0.0,
+// "fit": "contain", "align": "L", "valign": "T", "flow": false, "fill": "", "stroke": ""
+func (p *JSONGOFPDF) SVG(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
+	src := p.GetString("src", logic, "")
+	dataPath := p.GetString("data", logic, "")
+	content := p.GetString("content", logic, "")
+	name := p.GetString("name", logic, "")
+	x := p.GetFloat("x", logic, 0.0)
+	y := p.GetFloat("y", logic, 0.0)
+	width := p.GetFloat("width", logic, 0.0)
+	height := p.GetFloat("height", logic, 0.0)
+	fit := p.GetString("fit", logic, "contain")
+	align := p.GetString("align", logic, "L")
+	valign := p.GetString("valign", logic, "T")
+	flow := p.GetBool("flow", logic, false)
+	fill := p.GetString("fill", logic, "")
+	stroke := p.GetString("stroke", logic, "")
+
+	if name == "" {
+		name = src
+		if dataPath != "" {
+			name = "data:" + dataPath
+		}
+	}
+
+	svg, ok := p.svgs[name]
+	if !ok || name == "" {
+		var data []byte
+		var err error
+		if content != "" {
+			text, _ := jsonparser.ParseString([]byte(content))
+			data = []byte(text)
+		} else if dataPath != "" {
+			data, err = p.GetDataImage(dataPath)
+		} else {
+			data, err = p.GetImageData(src)
+		}
+		if err == nil {
+			svg, err = p.RegisterSVG(name, data)
+		}
+		if err != nil {
+			fmt.Println(err)
+			return pdf
+		}
+	}
+
+	if flow {
+		y = pdf.GetY()
+	}
+	_, boxHeight := p.DrawSVG(pdf, svg, x, y, width, height, fit, align, valign, fill, stroke)
+	if flow {
+		pdf.SetY(y + boxHeight)
+	}
+
+	return pdf
+}
+
+// RegisterSVG parses data and keeps it under name for reuse by later svg and image operations.
+func (p *JSONGOFPDF) RegisterSVG(name string, data []byte) (*SVG, error) {
+	svg, err := ParseSVG(data)
+	if err != nil {
+		return nil, err
+	}
+	if name != "" {
+		if p.svgs == nil {
+			p.svgs = make(map[string]*SVG)
+		}
+		p.svgs[name] = svg
+	}
+	return svg, nil
+}
@@ -0,0 +1,209 @@
+package jsongofpdf
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+
+	"github.com/buger/jsonparser"
+	"github.com/jung-kurt/gofpdf"
+	"github.com/spf13/cast"
+)
+
+// TableColumn is a column of a table operation, rendering the cell with the Key or Path in each row.
+type TableColumn struct {
+	Key    string
+	Header string
+	Width  string
+	Align  string
+	Format string
+	Merge  bool
+}
+
+// GetTableColumns reads the "columns" array of a table operation. Each column takes a "key" string as the key or path of
+// its cells, "header" string which defaults to the title of the cell in the first row, "width" as a number, a percentage
+// of the table width, "auto" to fit the content or a fraction of the remaining width such as "1fr", "align" string and
+// "format" string as for MultiCell and "merge" bool to merge the cells of consecutive rows with equal values into one.
+// Defaults are "width": "auto", "align": "L", "format": "", "merge": false
+func (p *JSONGOFPDF) GetTableColumns(logic string) (columns []TableColumn) {
+	jsonparser.ArrayEach([]byte(p.GetString("columns", logic, "")), func(value []byte, dataType jsonparser.ValueType, offset int, err error) {
+		switch dataType {
+		case jsonparser.Object:
+			column := TableColumn{
+				Key:    p.GetString("key", string(value), ""),
+				Header: p.GetString("header", string(value), ""),
+				Width:  strings.ToLower(strings.TrimSpace(p.GetString("width", string(value), "auto"))),
+				Align:  p.GetString("align", string(value), "L"),
+				Format: p.GetString("format", string(value), ""),
+				Merge:  p.GetBool("merge", string(value), false),
+			}
+			if column.Header == "" && len(p.Tables) > p.TableIndex && len(p.Tables[p.TableIndex].Rows) > 0 {
+				for _, cell := range p.Tables[p.TableIndex].Rows[0].Cells {
+					if cell.Key == column.Key || cell.Path == column.Key {
+						column.Header = cell.Title
+					}
+				}
+			}
+			columns = append(columns, column)
+			break
+		}
+	})
+	return columns
+}
+
+// ColumnWidths resolves the widths of the columns within the available width. Fixed and percentage widths are used as
+// given, auto widths take their content width and fractions share what is left. Auto columns shrink proportionally when
+// the columns do not fit.
+func ColumnWidths(columns []TableColumn, available float64, contentWidths []float64) (widths []float64) {
+	widths = make([]float64, len(columns))
+	used := 0.0
+	autoWidth := 0.0
+	fractions := 0.0
+	for index, column := range columns {
+		switch {
+		case column.Width == "auto":
+			widths[index] = contentWidths[index]
+			autoWidth += widths[index]
+			break
+		case strings.HasSuffix(column.Width, "fr"):
+			fractions += cast.ToFloat64(strings.TrimSuffix(column.Width, "fr"))
+			break
+		case strings.HasSuffix(column.Width, "%"):
+			widths[index] = available * cast.ToFloat64(strings.TrimSuffix(column.Width, "%")) / 100
+			used += widths[index]
+			break
+		default:
+			widths[index] = cast.ToFloat64(column.Width)
+			used += widths[index]
+			break
+		}
+	}
+
+	if autoWidth > 0 && used+autoWidth > available {
+		scale := math.Max(available-used, 0) / autoWidth
+		for index, column := range columns {
+			if column.Width == "auto" {
+				widths[index] *= scale
+			}
+		}
+		autoWidth *= scale
+	}
+
+	if fractions > 0 {
+		remaining := math.Max(available-used-autoWidth, 0)
+		for index, column := range columns {
+			if strings.HasSuffix(column.Width, "fr") {
+				widths[index] = remaining * cast.ToFloat64(strings.TrimSuffix(column.Width, "fr")) / fractions
+			}
+		}
+	}
+	return widths
+}
+
+// columnContentWidths measures the widest line of the header and of every cell of each column, including cell margins.
+func (p *JSONGOFPDF) columnContentWidths(pdf *gofpdf.Fpdf, columns []TableColumn, headerStyle string) (widths []float64) {
+	margin := 2*pdf.GetCellMargin() + 0.01
+	widest := func(text string, width float64) float64 {
+		for _, line := range strings.Split(p.tr(strings.Replace(text, "<br>", "\n", -1)), "\n") {
+			width = math.Max(width, pdf.GetStringWidth(line)+margin)
+		}
+		return width
+	}
+
+	widths = make([]float64, len(columns))
+	textStyle := &TextStyle{Style: headerStyle, styled: true}
+	p.ApplyTextStyle(pdf, textStyle)
+	for index, column := range columns {
+		widths[index] = widest(column.Header, 0)
+	}
+	p.RestoreTextStyle(pdf, textStyle)
+
+	if len(p.Tables) <= p.TableIndex {
+		return widths
+	}
+	for _, row := range p.Tables[p.TableIndex].Rows {
+		for _, cell := range row.Cells {
+			for index, column := range columns {
+				if cell.Key != column.Key && cell.Path != column.Key {
+					continue
+				}
+				if cell.Colspan <= 1 {
+					widths[index] = widest(p.tableCellText(cell, column), widths[index])
+				}
+			}
+		}
+	}
+	return widths
+}
+
+// TableOperation maps json to a table laid out by columns rather than by positioning each cell, rendered through TableFunc
+// with a header repeated on every page. Pass "index" int for the table, "columns" array, see GetTableColumns, "x" float
+// and "width" float for the position and width of the table, "height" float line height, "border" string for every cell,
+// "headerstyle" string and "headerfill" bool for the header and "valign" string for the body cells. Pass "headerrows" as an
+// array of header rows, see GetTableHeaderRows, to replace the column headers with merged header cells. Cells span columns
+// and rows by their Colspan and Rowspan, or by merging equal values of columns with "merge". "sort", "filter", "groups",
+// "footer", "pageheader", "pagefooter", "keeptogether" and "allowsplit" are passed to TableFunc.
+// Defaults are "index": 0, "x": left margin, "width": page width within the margins, "height": 5.0, "border": "1",
+// "headerstyle": "B", "headerfill": false, "valign": "top"
+func (p *JSONGOFPDF) TableOperation(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
+	p.TableIndex = p.GetInt("index", logic, 0)
+	columns := p.GetTableColumns(logic)
+	if len(columns) == 0 {
+		p.TableIndex = 0
+		return pdf
+	}
+
+	leftMargin, _, rightMargin, _ := pdf.GetMargins()
+	layout := &tableLayout{
+		columns:     columns,
+		height:      p.GetFloat("height", logic, 5.0),
+		border:      p.GetString("border", logic, "1"),
+		valign:      p.GetString("valign", logic, "top"),
+		headerStyle: p.GetString("headerstyle", logic, "B"),
+		headerFill:  p.GetBool("headerfill", logic, false),
+		spans:       make(map[int]*tableSpan),
+	}
+	pageWidth, _ := pdf.GetPageSize()
+	x := p.GetFloat("x", logic, leftMargin)
+	width := p.GetFloat("width", logic, pageWidth-leftMargin-rightMargin)
+	layout.headerRows = p.GetTableHeaderRows(logic)
+	if len(layout.headerRows) == 0 {
+		row := make([]TableHeaderCell, len(columns))
+		for index, column := range columns {
+			row[index] = TableHeaderCell{Text: column.Header, Colspan: 1, Rowspan: 1, Align: column.Align}
+		}
+		layout.headerRows = [][]TableHeaderCell{row}
+	}
+
+	layout.widths = ColumnWidths(columns, width, p.columnContentWidths(pdf, columns, layout.headerStyle))
+	layout.x = make([]float64, len(columns))
+	for index := range columns {
+		layout.x[index] = x
+		x += layout.widths[index]
+	}
+
+	body := make([]map[string]interface{}, 0)
+	for index := range columns {
+		body = append(body, map[string]interface{}{"tablecell": map[string]interface{}{"column": index}})
+	}
+	tableLogic := map[string]interface{}{
+		"index":  p.TableIndex,
+		"header": []map[string]interface{}{{"tableheader": map[string]interface{}{}}},
+		"body":   []map[string]interface{}{{"row": body}},
+	}
+	for _, name := range []string{"sort", "filter", "groups", "footer", "pageheader", "pagefooter", "keeptogether", "allowsplit"} {
+		if value, dataType, _, err := jsonparser.Get([]byte(logic), name); err == nil {
+			tableLogic[name] = json.RawMessage(value)
+			if dataType == jsonparser.String {
+				tableLogic[name] = string(value)
+			}
+		}
+	}
+
+	// The values are maps, strings and json already parsed from logic, which always marshal
+	tableJSON, _ := json.Marshal(tableLogic)
+	p.tableLayout = layout
+	pdf = p.TableFunc(pdf, string(tableJSON))
+	p.tableLayout = nil
+	return pdf
+}
@@ -0,0 +1,71 @@
+package jsongofpdf
+
+import (
+	"math"
+	"testing"
+)
+
+func TestColumnWidths(t *testing.T) {
+	tests := []struct {
+		widths  []string
+		content []float64
+		want    []float64
+	}{
+		{[]string{"auto", "1fr", "30", "10%"}, []float64{20, 50, 0, 0}, []float64{20, 130, 30, 20}},
+		{[]string{"1fr", "3fr"}, []float64{0, 0}, []float64{50, 150}},
+		{[]string{"auto", "auto", "100"}, []float64{150, 50, 0}, []float64{75, 25, 100}},
+		{[]string{"auto", "1fr"}, []float64{250, 0}, []float64{200, 0}},
+	}
+	for _, test := range tests {
+		columns := make([]TableColumn, len(test.widths))
+		for index, width := range test.widths {
+			columns[index].Width = width
+		}
+		widths := ColumnWidths(columns, 200, test.content)
+		for index := range widths {
+			if math.Abs(widths[index]-test.want[index]) > 0.001 {
+				t.Fatalf("ColumnWidths(%v) = %v, want %v", test.widths, widths, test.want)
+			}
+		}
+	}
+}
+
+func TestSpanBorder(t *testing.T) {
+	tests := []struct {
+		border      string
+		top, bottom bool
+		want        string
+	}{
+		{"1", true, true, "LTRB"},
+		{"1", true, false, "LTR"},
+		{"1", false, false, "LR"},
+		{"LB", false, true, "LB"},
+		{"", false, false, ""},
+	}
+	for _, test := range tests {
+		if border := spanBorder(test.border, test.top, test.bottom); border != test.want {
+			t.Fatalf("spanBorder(%q, %v, %v) = %q, want %q", test.border, test.top, test.bottom, border, test.want)
+		}
+	}
+}
+
+func TestTableOperationLayout(t *testing.T) {
+	logic := `[{"new": {}}, {"setfont": {"size": 10}}, {"addpage": {}}, {"sety": {"y": 30}}, {"table": {"x": 20, "height": 6,
+		"columns": [{"key": "item", "width": 40}, {"key": "amount", "header": "Total", "width": "30"}]}}]`
+	pages := renderPages(t, logic, []Table{amountTable(3)})
+	want := []string{"Item", "Total", "item 1", "1", "item 2", "2", "item 3", "3"}
+	if len(pages) != 1 || len(pages[0]) != len(want) {
+		t.Fatalf("table should render its header and 3 rows on one page, got %v", pages)
+	}
+
+	// Text starts a 1mm cell margin into its column and each line sits 6mm below the last, the header in the first
+	for index, text := range pages[0] {
+		x, y := []float64{21, 61}[index%2], 30+float64(index/2)*6
+		if text.text != want[index] || math.Abs(text.x-x) > 0.01 || text.y < y || text.y > y+6 {
+			t.Fatalf("%q should be drawn at %.0fmm in the line from %.0fmm, got %q at %.2fmm, %.2fmm", want[index], x, y, text.text, text.x, text.y)
+		}
+		if index%2 == 1 && text.y != pages[0][index-1].y {
+			t.Fatalf("%q should be on the line of %q", text.text, pages[0][index-1].text)
+		}
+	}
+}
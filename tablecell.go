@@ -0,0 +1,426 @@
+package jsongofpdf
+
+import (
+	"math"
+	"strings"
+
+	"github.com/buger/jsonparser"
+	"github.com/jung-kurt/gofpdf"
+	"github.com/spf13/cast"
+)
+
+// tableLayout is the column layout of the table operation being rendered, used by the tableheader and tablecell operations.
+type tableLayout struct {
+	columns     []TableColumn
+	x           []float64
+	widths      []float64
+	height      float64
+	border      string
+	valign      string
+	headerStyle string
+	headerFill  bool
+	headerRows  [][]TableHeaderCell
+	// spans are the cells spanning rows, by the column they start in
+	spans map[int]*tableSpan
+}
+
+// tableSpan is a cell spanning from the row it starts in to its last row. The lines of its text flow down its rows, each
+// row taking the lines that fit in it and the last row growing to fit the lines left.
+type tableSpan struct {
+	start   int
+	last    int
+	columns int
+	lines   []string
+	drawn   int
+	page    int
+}
+
+// TableHeaderCell is a cell of a header row of a table operation.
+type TableHeaderCell struct {
+	Text    string
+	Colspan int
+	Rowspan int
+	Align   string
+}
+
+// GetTableHeaderRows reads the "headerrows" array of a table operation. Each header row is an array of cells placed from
+// left to right in the columns not taken by cells spanning down from the rows above. Each cell takes a "text" string,
+// "colspan" and "rowspan" int and "align" string. Defaults are "colspan": 1, "rowspan": 1, "align": "C"
+func (p *JSONGOFPDF) GetTableHeaderRows(logic string) (rows [][]TableHeaderCell) {
+	jsonparser.ArrayEach([]byte(p.GetString("headerrows", logic, "")), func(value []byte, dataType jsonparser.ValueType, offset int, err error) {
+		switch dataType {
+		case jsonparser.Array:
+			row := make([]TableHeaderCell, 0)
+			jsonparser.ArrayEach(value, func(value []byte, dataType jsonparser.ValueType, offset int, err error) {
+				switch dataType {
+				case jsonparser.Object:
+					row = append(row, TableHeaderCell{
+						Text:    p.GetString("text", string(value), ""),
+						Colspan: int(math.Max(float64(p.GetInt("colspan", string(value), 1)), 1)),
+						Rowspan: int(math.Max(float64(p.GetInt("rowspan", string(value), 1)), 1)),
+						Align:   p.GetString("align", string(value), "C"),
+					})
+					break
+				}
+			})
+			rows = append(rows, row)
+			break
+		}
+	})
+	return rows
+}
+
+// tableCellText returns the text of a cell as rendered in a column, formatted by the column or the type of the cell.
+func (p *JSONGOFPDF) tableCellText(cell Cell, column TableColumn) string {
+	format := column.Format
+	if cell.Type == "currency" || cell.Type == "date" {
+		format = cell.Type
+	}
+	text := cast.ToString(cell.Value)
+	if format != "" {
+		text = p.Format(format, text)
+	}
+	return text
+}
+
+// tableRowCell returns the cell of a row rendered in a column.
+func (p *JSONGOFPDF) tableRowCell(row int, column TableColumn) (cell Cell) {
+	rows := p.Tables[p.TableIndex].Rows
+	if row >= len(rows) {
+		return cell
+	}
+	for _, rowCell := range rows[row].Cells {
+		if rowCell.Key == column.Key || rowCell.Path == column.Key {
+			cell = rowCell
+		}
+	}
+	return cell
+}
+
+// tableCellSpan returns how many columns and rows the cell of the current row in a column spans. A column that merges
+// equal values spans the following rows with the same value, within the same groups.
+func (p *JSONGOFPDF) tableCellSpan(layout *tableLayout, index int) (cell Cell, columns int, rows int) {
+	column := layout.columns[index]
+	cell = p.tableRowCell(p.RowIndex, column)
+	columns = int(math.Min(math.Max(float64(cell.Colspan), 1), float64(len(layout.columns)-index)))
+	rows = int(math.Max(float64(cell.Rowspan), 1))
+	if column.Merge && rows == 1 {
+		value := cast.ToString(cell.Value)
+		for row := p.RowIndex + 1; row < len(p.Tables[p.TableIndex].Rows); row++ {
+			if cast.ToString(p.tableRowCell(row, column).Value) != value {
+				break
+			}
+			if len(p.groups) > 0 && p.groupLevel(row) < len(p.groups) {
+				break
+			}
+			rows++
+		}
+	}
+	rows = int(math.Min(float64(rows), float64(len(p.Tables[p.TableIndex].Rows)-p.RowIndex)))
+	return cell, columns, rows
+}
+
+// tableCellCovered returns whether a column of the current row is covered by a cell spanning from an earlier column of the
+// row or from a row above, and the span from above when it starts in this column.
+func (p *JSONGOFPDF) tableCellCovered(layout *tableLayout, index int) (covered bool, span *tableSpan) {
+	for start, rowSpan := range layout.spans {
+		if rowSpan.start < p.RowIndex && p.RowIndex <= rowSpan.last && start <= index && index < start+rowSpan.columns {
+			if start == index {
+				return true, rowSpan
+			}
+			return true, nil
+		}
+	}
+	for start := 0; start < index; start++ {
+		if covered, _ := p.tableCellCovered(layout, start); covered {
+			continue
+		}
+		if _, columns, _ := p.tableCellSpan(layout, start); start+columns > index {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// tableSpanWidth is the width of a cell spanning columns from a column.
+func tableSpanWidth(layout *tableLayout, index int, columns int) (width float64) {
+	for column := index; column < index+columns; column++ {
+		width += layout.widths[column]
+	}
+	return width
+}
+
+// tableTextHeight is the height of the text of a cell wrapped to its width.
+func (p *JSONGOFPDF) tableTextHeight(pdf *gofpdf.Fpdf, text string, width float64, height float64) float64 {
+	lines := p.SplitText(pdf, p.tr(strings.Replace(text, "<br>", "\n", -1)), width, &TextStyle{})
+	return math.Max(float64(len(lines)), 1) * height
+}
+
+// startTableSpan records the cell of the current row in a column spanning rows, with its text split into the lines shared
+// over its rows. The span already recorded for the row is returned when there is one.
+func (p *JSONGOFPDF) startTableSpan(pdf *gofpdf.Fpdf, layout *tableLayout, index int, text string, columns int, rows int) *tableSpan {
+	if span := layout.spans[index]; span != nil && span.start == p.RowIndex {
+		return span
+	}
+	width := tableSpanWidth(layout, index, columns)
+	span := &tableSpan{
+		start:   p.RowIndex,
+		last:    p.RowIndex + rows - 1,
+		columns: columns,
+		lines:   p.SplitText(pdf, p.tr(strings.Replace(text, "<br>", "\n", -1)), width, &TextStyle{}),
+	}
+	layout.spans[index] = span
+	return span
+}
+
+// spanBorder drops the top border of a cell continuing a span from the row above and the bottom border of a cell
+// continuing on the row below.
+func spanBorder(border string, top bool, bottom bool) string {
+	if border == "" {
+		return border
+	}
+	if border == "1" {
+		border = "LTRB"
+	}
+	if !top {
+		border = strings.Replace(border, "T", "", -1)
+	}
+	if !bottom {
+		border = strings.Replace(border, "B", "", -1)
+	}
+	return border
+}
+
+// PreRowTableCell counts the height of a table operation cell towards the row height before rendering the row. A cell
+// spanning rows only counts on its last row, for the lines of its text the rows before it did not take.
+func (p *JSONGOFPDF) PreRowTableCell(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
+	layout := p.tableLayout
+	index := p.GetInt("column", logic, 0)
+	if layout == nil || index >= len(layout.columns) || p.inTableSection {
+		return pdf
+	}
+
+	height := 0.0
+	if covered, span := p.tableCellCovered(layout, index); covered {
+		if span == nil || span.last != p.RowIndex {
+			return pdf
+		}
+		height = float64(len(span.lines)-span.drawn) * layout.height
+	} else {
+		cell, columns, rows := p.tableCellSpan(layout, index)
+		text := p.tableCellText(cell, layout.columns[index])
+		if rows > 1 {
+			p.startTableSpan(pdf, layout, index, text, columns, rows)
+			return pdf
+		}
+		height = p.tableTextHeight(pdf, text, tableSpanWidth(layout, index, columns), layout.height)
+	}
+
+	if height > p.RowHeight {
+		p.RowHeight = height
+	}
+	if cells := math.Ceil(height/layout.height - 0.000001); cells > p.RowCells {
+		p.RowCells = cells
+	}
+	return pdf
+}
+
+// TableCell renders the cell of the current row in a column of the table operation, as the box of the full row height
+// with its text aligned inside it. Cells spanning columns take their width and cells spanning rows are drawn row by row,
+// see tableSpanRow, so rows of a span can break onto the next page like any other. In a row split across pages the cell
+// is drawn line by line so it can continue on the next page.
+func (p *JSONGOFPDF) TableCell(pdf *gofpdf.Fpdf, logic string) (opdf *gofpdf.Fpdf) {
+	layout := p.tableLayout
+	index := p.GetInt("column", logic, 0)
+	if layout == nil || index >= len(layout.columns) {
+		return pdf
+	}
+
+	rowHeight := math.Max(p.RowHeight, layout.height)
+	covered, span := p.tableCellCovered(layout, index)
+	if covered && span == nil {
+		return pdf
+	}
+
+	p.rowSplitStart(pdf)
+	pdf.SetX(layout.x[index])
+	if span != nil {
+		p.tableSpanRow(pdf, layout, index, span, rowHeight)
+	} else if cell, columns, rows := p.tableCellSpan(layout, index); rows > 1 {
+		span = p.startTableSpan(pdf, layout, index, p.tableCellText(cell, layout.columns[index]), columns, rows)
+		p.tableSpanRow(pdf, layout, index, span, rowHeight)
+	} else {
+		width := tableSpanWidth(layout, index, columns)
+		text := p.tableCellText(cell, layout.columns[index])
+		textHeight := p.tableTextHeight(pdf, text, width, layout.height)
+		text = p.tr(strings.Replace(text, "<br>", "\n", -1))
+
+		if p.rowSplit != nil {
+			p.tableCellLines(pdf, layout, index, width, text, layout.columns[index].Align, true, true, math.Max(p.RowCells, 1))
+		} else {
+			pdf.CellFormat(width, rowHeight, "", layout.border, 0, "", false, 0, "")
+			cellY := pdf.GetY()
+			offset := 0.0
+			switch layout.valign {
+			case "middle":
+				offset = (rowHeight - textHeight) / 2
+				break
+			case "bottom":
+				offset = rowHeight - textHeight
+				break
+			}
+			if text != "" {
+				pdf.SetXY(layout.x[index], cellY+math.Max(offset, 0))
+				p.SpacedMultiCell(pdf, width, layout.height, text, "", layout.columns[index].Align, false, &TextStyle{})
+			}
+			pdf.SetY(cellY + rowHeight)
+		}
+	}
+
+	if pdf.GetY() > p.NextY {
+		p.NextY = pdf.GetY()
+	}
+	return pdf
+}
+
+// tableSpanRow draws a cell spanning rows on the current row, as the box of the row with the lines of its text that fit in
+// it, or all lines left on the last row of the span. The box is open to the rows of the span above and below it, but is
+// closed at the top when the span continues on a new page.
+func (p *JSONGOFPDF) tableSpanRow(pdf *gofpdf.Fpdf, layout *tableLayout, index int, span *tableSpan, rowHeight float64) {
+	top := span.start == p.RowIndex || pdf.PageNo() != span.page
+	last := span.last == p.RowIndex
+	count := len(span.lines) - span.drawn
+	if !last {
+		count = int(math.Min(float64(count), math.Floor(rowHeight/layout.height+0.000001)))
+	}
+	text := strings.Join(span.lines[span.drawn:span.drawn+count], "\n")
+	span.drawn += count
+
+	width := tableSpanWidth(layout, index, span.columns)
+	align := layout.columns[index].Align
+	if p.rowSplit != nil {
+		p.tableCellLines(pdf, layout, index, width, text, align, top, last, math.Max(p.RowCells, 1))
+	} else {
+		cellY := pdf.GetY()
+		pdf.CellFormat(width, rowHeight, "", spanBorder(layout.border, top, last), 0, "", false, 0, "")
+		if text != "" {
+			pdf.SetXY(layout.x[index], cellY)
+			p.SpacedMultiCell(pdf, width, layout.height, text, "", align, false, &TextStyle{})
+		}
+		pdf.SetY(cellY + rowHeight)
+	}
+
+	span.page = pdf.PageNo()
+	if last {
+		delete(layout.spans, index)
+	}
+}
+
+// tableCellLines draws a cell as its lines of text padded with empty lines to the given number of lines, bordered like
+// MultiCell so the cell can break across pages line by line.
+func (p *JSONGOFPDF) tableCellLines(pdf *gofpdf.Fpdf, layout *tableLayout, index int, width float64, text string, align string, top bool, bottom bool, lines float64) {
+	x := pdf.GetX()
+	textLines := 0.0
+	if text != "" {
+		textLines = float64(len(p.SplitText(pdf, text, width, &TextStyle{})))
+		p.SpacedMultiCell(pdf, width, layout.height, text, spanBorder(layout.border, top, bottom && textLines >= lines), align, false, &TextStyle{})
+	}
+	for line := textLines; line < lines; line++ {
+		pdf.SetX(x)
+		pdf.CellFormat(width, layout.height, "", spanBorder(layout.border, top && line == 0, bottom && line == lines-1), 2, "", false, 0, "")
+	}
+}
+
+// TableHeaderRows renders the header rows of the table operation at the current position. Header cells spanning rows
+// share the height of those rows, with the last of them growing to fit, and are aligned to the middle of their box.
+func (p *JSONGOFPDF) TableHeaderRows(pdf *gofpdf.Fpdf) (opdf *gofpdf.Fpdf) {
+	layout := p.tableLayout
+	if layout == nil {
+		return pdf
+	}
+
+	type placedCell struct {
+		cell   TableHeaderCell
+		row    int
+		column int
+		width  float64
+		height float64
+	}
+	placed := make([]placedCell, 0)
+	taken := make(map[[2]int]bool)
+	heights := make([]float64, len(layout.headerRows))
+
+	textStyle := &TextStyle{Style: layout.headerStyle, styled: true}
+	p.ApplyTextStyle(pdf, textStyle)
+	for row, cells := range layout.headerRows {
+		column := 0
+		for _, cell := range cells {
+			for taken[[2]int{row, column}] {
+				column++
+			}
+			if column >= len(layout.columns) {
+				break
+			}
+			cell.Colspan = int(math.Min(float64(cell.Colspan), float64(len(layout.columns)-column)))
+			cell.Rowspan = int(math.Min(float64(cell.Rowspan), float64(len(layout.headerRows)-row)))
+			for spanRow := row; spanRow < row+cell.Rowspan; spanRow++ {
+				for spanColumn := column; spanColumn < column+cell.Colspan; spanColumn++ {
+					taken[[2]int{spanRow, spanColumn}] = true
+				}
+			}
+			width := tableSpanWidth(layout, column, cell.Colspan)
+			height := p.tableTextHeight(pdf, cell.Text, width, layout.height)
+			placed = append(placed, placedCell{cell: cell, row: row, column: column, width: width, height: height})
+			if cell.Rowspan == 1 && height > heights[row] {
+				heights[row] = height
+			}
+			column += cell.Colspan
+		}
+		if heights[row] == 0 {
+			heights[row] = layout.height
+		}
+	}
+
+	// Cells spanning rows grow the last of their rows when the rows are not tall enough for them
+	for _, cell := range placed {
+		last := cell.row + cell.cell.Rowspan - 1
+		spanned := 0.0
+		for row := cell.row; row <= last; row++ {
+			spanned += heights[row]
+		}
+		if cell.height > spanned {
+			heights[last] += cell.height - spanned
+		}
+	}
+
+	total := 0.0
+	for _, height := range heights {
+		total += height
+	}
+	p.PageBreak(pdf, total)
+
+	top := pdf.GetY()
+	for _, cell := range placed {
+		y := top
+		for row := 0; row < cell.row; row++ {
+			y += heights[row]
+		}
+		height := 0.0
+		for row := cell.row; row < cell.row+cell.cell.Rowspan; row++ {
+			height += heights[row]
+		}
+		pdf.SetXY(layout.x[cell.column], y)
+		pdf.CellFormat(cell.width, height, "", layout.border, 0, "", layout.headerFill, 0, "")
+		if cell.cell.Text != "" {
+			pdf.SetXY(layout.x[cell.column], y+(height-cell.height)/2)
+			p.SpacedMultiCell(pdf, cell.width, layout.height, p.tr(strings.Replace(cell.cell.Text, "<br>", "\n", -1)), "", cell.cell.Align, false, textStyle)
+		}
+	}
+	p.RestoreTextStyle(pdf, textStyle)
+
+	pdf.SetY(top + total)
+	if pdf.GetY() > p.NextY {
+		p.NextY = pdf.GetY()
+	}
+	return pdf
+}
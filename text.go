@@ -0,0 +1,425 @@
+package jsongofpdf
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// TextStyle holds the "style", "letterspacing", "wordspacing" and "hyphenate" object properties shared by the text operations.
+// Style accepts the gofpdf font style letters including "U" for underline and "S" for strike-through.
+type TextStyle struct {
+	Style         string
+	LetterSpacing float64
+	WordSpacing   float64
+	Hyphenate     string
+	styled        bool
+	fontStyle     string
+}
+
+// GetTextStyle reads the text decoration and spacing object properties from json logic.
+// Defaults are "style": the style passed to setfont, "letterspacing": 0.0, "wordspacing": 0.0,
+// "hyphenate": the language passed to sethyphenation, "none" turns hyphenation off
+func (p *JSONGOFPDF) GetTextStyle(logic string) *TextStyle {
+	style := &TextStyle{
+		LetterSpacing: p.GetFloat("letterspacing", logic, 0.0),
+		WordSpacing:   p.GetFloat("wordspacing", logic, 0.0),
+		Hyphenate:     p.GetString("hyphenate", logic, p.Hyphenation),
+	}
+	if style.Hyphenate == "none" {
+		style.Hyphenate = ""
+	}
+	if _, _, _, err := p.GetAttribute("style", logic, false); err == nil {
+		style.Style = strings.ToUpper(p.GetString("style", logic, ""))
+		style.styled = true
+	}
+	return style
+}
+
+// Spaced reports whether the text needs letter or word spacing applied.
+func (s *TextStyle) Spaced() bool {
+	return s.LetterSpacing != 0 || s.WordSpacing != 0
+}
+
+// Measured reports whether lines have to be split by SplitText rather than gofpdf, for spacing or hyphenation.
+func (s *TextStyle) Measured() bool {
+	return s.Spaced() || s.Hyphenate != ""
+}
+
+// ApplyTextStyle switches the current font to the style of the operation. Call RestoreTextStyle once the text is written.
+func (p *JSONGOFPDF) ApplyTextStyle(pdf *gofpdf.Fpdf, style *TextStyle) {
+	if style.styled {
+		style.fontStyle = p.FontStyle
+		p.FontStyle = style.Style
+		pdf.SetFont("", style.Style, 0)
+	}
+}
+
+// RestoreTextStyle switches the font back to the style set before ApplyTextStyle.
+func (p *JSONGOFPDF) RestoreTextStyle(pdf *gofpdf.Fpdf, style *TextStyle) {
+	if style.styled {
+		p.FontStyle = style.fontStyle
+		pdf.SetFont("", style.fontStyle, 0)
+	}
+}
+
+// TextWidth returns the width of text in the current font including letter and word spacing.
+func (p *JSONGOFPDF) TextWidth(pdf *gofpdf.Fpdf, text string, style *TextStyle) float64 {
+	return pdf.GetStringWidth(text) + style.LetterSpacing*float64(len(textChars(pdf, text))) + style.WordSpacing*float64(strings.Count(text, " "))
+}
+
+// utf8Font reports whether the current font was added with AddUTF8Font. gofpdf does not export this, so it is read by
+// reflection.
+func utf8Font(pdf *gofpdf.Fpdf) bool {
+	field := reflect.ValueOf(pdf).Elem().FieldByName("isCurrentUTF8")
+	return field.IsValid() && field.Bool()
+}
+
+// textChars splits text into its characters, which are runes for UTF-8 fonts and bytes for the core fonts as their text
+// is translated to a single byte code page.
+func textChars(pdf *gofpdf.Fpdf, text string) []string {
+	if utf8Font(pdf) {
+		return strings.Split(text, "")
+	}
+	chars := make([]string, len(text))
+	for index := range chars {
+		chars[index] = text[index : index+1]
+	}
+	return chars
+}
+
+// SplitText splits text into lines that fit width the same way gofpdf SplitLines does, measuring letter and word spacing when set
+// and hyphenating words that do not fit when a hyphenation language is set.
+func (p *JSONGOFPDF) SplitText(pdf *gofpdf.Fpdf, text string, width float64, style *TextStyle) []string {
+	lines := make([]string, 0)
+	if !style.Measured() {
+		for _, line := range pdf.SplitLines([]byte(text), width) {
+			lines = append(lines, string(line))
+		}
+		return lines
+	}
+
+	if width == 0 {
+		pageWidth, _ := pdf.GetPageSize()
+		_, _, rightMargin, _ := pdf.GetMargins()
+		width = pageWidth - rightMargin - pdf.GetX()
+	}
+	maxWidth := width - 2*pdf.GetCellMargin()
+
+	text = strings.TrimRight(strings.Replace(text, "\r", "", -1), "\n")
+	if text == "" {
+		return lines
+	}
+
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Split(paragraph, " ") {
+			for {
+				candidate := word
+				if line != "" {
+					candidate = line + " " + word
+				}
+				if p.TextWidth(pdf, candidate, style) <= maxWidth {
+					line = candidate
+					break
+				}
+				// Hyphenate the word to fill the line before moving it to the next line
+				if style.Hyphenate != "" {
+					if head, tail := p.hyphenateWord(pdf, line, word, maxWidth, style); head != "" {
+						if line != "" {
+							head = line + " " + head
+						}
+						lines = append(lines, head)
+						line, word = "", tail
+						continue
+					}
+				}
+				if line != "" {
+					lines = append(lines, line)
+					line = ""
+					continue
+				}
+				// Words wider than the cell are broken between characters
+				for _, char := range textChars(pdf, word) {
+					if line != "" && p.TextWidth(pdf, line+char, style) > maxWidth {
+						lines = append(lines, line)
+						line = ""
+					}
+					line += char
+				}
+				break
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// PageBreak adds a new page when height no longer fits above the automatic page break margin, keeping the current x position.
+func (p *JSONGOFPDF) PageBreak(pdf *gofpdf.Fpdf, height float64) bool {
+	auto, margin := pdf.GetAutoPageBreak()
+	_, pageHeight := pdf.GetPageSize()
+	if auto && pdf.GetY()+height > pageHeight-margin {
+		x := pdf.GetX()
+		pdf.AddPage()
+		pdf.SetX(x)
+		return true
+	}
+	return false
+}
+
+// SpacedCellFormat writes a gofpdf CellFormat applying letter and word spacing, aligning and decorating the text by its spaced width.
+func (p *JSONGOFPDF) SpacedCellFormat(pdf *gofpdf.Fpdf, width float64, height float64, text string, border string, ln int, align string, fill bool, link int, linkStr string, style *TextStyle) {
+	if !style.Spaced() {
+		pdf.CellFormat(width, height, text, border, ln, align, fill, link, linkStr)
+		return
+	}
+
+	if width == 0 {
+		pageWidth, _ := pdf.GetPageSize()
+		_, _, rightMargin, _ := pdf.GetMargins()
+		width = pageWidth - rightMargin - pdf.GetX()
+	}
+
+	p.PageBreak(pdf, height)
+
+	// gofpdf aligns by the unspaced width, so the text is left aligned and offset through the cell margin instead
+	cellMargin := pdf.GetCellMargin()
+	textWidth := p.TextWidth(pdf, text, style)
+	offset := cellMargin
+	switch {
+	case strings.Contains(align, "R"):
+		offset = width - cellMargin - textWidth
+		break
+	case strings.Contains(align, "C"):
+		offset = (width - textWidth) / 2
+		break
+	}
+	vertical := ""
+	for _, char := range []string{"T", "B", "M", "A"} {
+		if strings.Contains(align, char) {
+			vertical = char
+		}
+	}
+
+	// gofpdf measures underline and strike-through without spacing, so they are drawn here instead
+	underline := strings.Contains(p.FontStyle, "U")
+	strikeout := strings.Contains(p.FontStyle, "S")
+	if underline || strikeout {
+		pdf.SetFont("", strings.NewReplacer("U", "", "S", "").Replace(p.FontStyle), 0)
+	}
+
+	x, y := pdf.GetXY()
+	k := pdf.GetConversionRatio()
+	if style.WordSpacing != 0 && utf8Font(pdf) {
+		// Tw only spaces single byte spaces, so the words of UTF-8 fonts are written one by one after the cell is drawn
+		pdf.CellFormat(width, height, "", border, ln, "L"+vertical, fill, link, linkStr)
+		endX, endY := pdf.GetXY()
+		pdf.RawWriteStr(fmt.Sprintf("%.3f Tc", style.LetterSpacing*k))
+		pdf.SetCellMargin(0)
+		wordX := x + offset
+		for _, word := range strings.Split(text, " ") {
+			pdf.SetXY(wordX, y)
+			pdf.CellFormat(p.TextWidth(pdf, word, style), height, word, "", 0, "L"+vertical, false, 0, "")
+			wordX += p.TextWidth(pdf, word+" ", style)
+		}
+		pdf.SetCellMargin(cellMargin)
+		pdf.RawWriteStr("0 Tc")
+		pdf.SetXY(endX, endY)
+	} else {
+		pdf.RawWriteStr(fmt.Sprintf("%.3f Tc %.3f Tw", style.LetterSpacing*k, style.WordSpacing*k))
+		pdf.SetCellMargin(offset)
+		pdf.CellFormat(width, height, text, border, ln, "L"+vertical, fill, link, linkStr)
+		pdf.SetCellMargin(cellMargin)
+		pdf.RawWriteStr("0 Tc 0 Tw")
+	}
+
+	if (underline || strikeout) && text != "" {
+		_, fontSize := pdf.GetFontSize()
+		baseline := y + height/2 + 0.3*fontSize
+		switch vertical {
+		case "T":
+			baseline += (fontSize - height) / 2
+			break
+		case "B":
+			baseline += (height - fontSize) / 2
+			break
+		}
+
+		fillR, fillG, fillB := pdf.GetFillColor()
+		pdf.SetFillColor(pdf.GetTextColor())
+		if underline {
+			pdf.Rect(x+offset, baseline+0.1*fontSize, textWidth, 0.05*fontSize, "F")
+		}
+		if strikeout {
+			pdf.Rect(x+offset, baseline-0.4*fontSize, textWidth, 0.05*fontSize, "F")
+		}
+		pdf.SetFillColor(fillR, fillG, fillB)
+		pdf.SetFont("", p.FontStyle, 0)
+	}
+}
+
+// SpacedMultiCell writes a gofpdf MultiCell applying letter and word spacing to each line.
+func (p *JSONGOFPDF) SpacedMultiCell(pdf *gofpdf.Fpdf, width float64, height float64, text string, border string, align string, fill bool, style *TextStyle) {
+	if !style.Measured() {
+		pdf.MultiCell(width, height, text, border, align, fill)
+		return
+	}
+
+	if width == 0 {
+		pageWidth, _ := pdf.GetPageSize()
+		_, _, rightMargin, _ := pdf.GetMargins()
+		width = pageWidth - rightMargin - pdf.GetX()
+	}
+
+	// Borders are split over the lines like gofpdf MultiCell does
+	first, middle := "", ""
+	if border == "1" {
+		border = "LTRB"
+	}
+	if strings.Contains(border, "L") {
+		middle += "L"
+	}
+	if strings.Contains(border, "R") {
+		middle += "R"
+	}
+	first = middle
+	if strings.Contains(border, "T") {
+		first += "T"
+	}
+
+	// Paragraphs are split separately so the last line of each is left aligned when justifying
+	lines := make([]string, 0)
+	ends := make(map[int]bool)
+	for _, paragraph := range strings.Split(strings.TrimRight(strings.Replace(text, "\r", "", -1), "\n"), "\n") {
+		paragraphLines := p.SplitText(pdf, paragraph, width, style)
+		if len(paragraphLines) == 0 {
+			paragraphLines = append(paragraphLines, "")
+		}
+		lines = append(lines, paragraphLines...)
+		ends[len(lines)-1] = true
+	}
+
+	x := pdf.GetX()
+	for i, line := range lines {
+		lineBorder := middle
+		if i == 0 {
+			lineBorder = first
+		}
+		if i == len(lines)-1 && strings.Contains(border, "B") {
+			lineBorder += "B"
+		}
+		lineStyle := style
+		lineAlign := align
+		if align == "J" || align == "" {
+			lineAlign = "L"
+			if spaces := strings.Count(line, " "); spaces > 0 && !ends[i] {
+				justified := *style
+				justified.WordSpacing += (width - 2*pdf.GetCellMargin() - p.TextWidth(pdf, line, style)) / float64(spaces)
+				lineStyle = &justified
+			}
+		}
+		pdf.SetX(x)
+		p.SpacedCellFormat(pdf, width, height, line, lineBorder, 2, lineAlign, fill, 0, "", lineStyle)
+	}
+
+	leftMargin, _, _, _ := pdf.GetMargins()
+	pdf.SetX(leftMargin)
+}
+
+// FitText applies the "fit" object property to text. "shrink" reduces the font size by "step" down to "minsize" until the text
+// fits, "ellipsis" truncates the text with "…". Single line text must fit within width, multiline text must also fit within
+// "maxlines" lines or "maxheight" when given. The font size is left changed for writing the text, so the caller restores it,
+// and the line height is returned scaled to the new font size.
+// Defaults are "fit": "", "minsize": 4.0, "step": 0.5, "maxlines": 0, "maxheight": 0.0
+func (p *JSONGOFPDF) FitText(pdf *gofpdf.Fpdf, logic string, text string, width float64, lineHeight float64, multiline bool, style *TextStyle) (string, float64) {
+	fit := p.GetString("fit", logic, "")
+	if fit == "" || text == "" {
+		return text, lineHeight
+	}
+
+	minSize := p.GetFloat("minsize", logic, 4.0)
+	step := p.GetFloat("step", logic, 0.5)
+	maxLines := p.GetInt("maxlines", logic, 0)
+	maxHeight := p.GetFloat("maxheight", logic, 0.0)
+
+	if width == 0 {
+		pageWidth, _ := pdf.GetPageSize()
+		_, _, rightMargin, _ := pdf.GetMargins()
+		width = pageWidth - rightMargin - pdf.GetX()
+	}
+	available := width - 2*pdf.GetCellMargin()
+
+	startSize, _ := pdf.GetFontSize()
+	scaledHeight := func() float64 {
+		size, _ := pdf.GetFontSize()
+		return lineHeight * size / startSize
+	}
+	allowedLines := func() int {
+		allowed := maxLines
+		if maxHeight > 0 && scaledHeight() > 0 {
+			heightLines := int(maxHeight/scaledHeight() + 0.000001)
+			if allowed == 0 || heightLines < allowed {
+				allowed = heightLines
+			}
+		}
+		return allowed
+	}
+	fits := func() bool {
+		if !multiline {
+			return p.TextWidth(pdf, text, style) <= available
+		}
+		allowed := allowedLines()
+		if allowed > 0 && len(p.SplitText(pdf, text, width, style)) > allowed {
+			return false
+		}
+		// A single word wider than the box is broken by SplitText, so words are checked separately
+		for _, word := range strings.Fields(text) {
+			if p.TextWidth(pdf, word, style) > available {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch fit {
+	case "shrink":
+		for size := startSize; !fits() && size-step >= minSize && step > 0; {
+			size -= step
+			pdf.SetFontSize(size)
+		}
+		break
+	case "ellipsis":
+		if fits() {
+			break
+		}
+		ellipsis := p.tr("…")
+		truncate := func(line string) string {
+			for len(line) > 0 && p.TextWidth(pdf, line+ellipsis, style) > available {
+				line = line[:len(line)-1]
+			}
+			return strings.TrimRight(line, " ") + ellipsis
+		}
+		if !multiline {
+			text = truncate(text)
+			break
+		}
+		lines := p.SplitText(pdf, text, width, style)
+		if allowed := allowedLines(); allowed > 0 && len(lines) > allowed {
+			lines = lines[:allowed]
+			lines[allowed-1] = truncate(lines[allowed-1])
+		}
+		for i, line := range lines {
+			if p.TextWidth(pdf, line, style) > available {
+				lines[i] = truncate(line)
+			}
+		}
+		text = strings.Join(lines, "\n")
+		break
+	}
+
+	return text, scaledHeight()
+}